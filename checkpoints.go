@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"cc-launcher/internal/git"
+	"cc-launcher/internal/ui"
+)
+
+const checkpointsUsage = "Usage: cc-launcher checkpoints list | checkpoints diff [name] [git diff options] | checkpoints restore [name]"
+
+// runCheckpoints lists, compares and restores the git checkpoints recorded
+// before launches in any worktree. Without a name diff and restore use the
+// newest one of the current worktree.
+func runCheckpoints(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(checkpointsUsage))
+		return 2
+	}
+
+	action, args := args[0], args[1:]
+	name := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	switch action {
+	case "list":
+		if name != "" || len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(checkpointsUsage))
+			return 2
+		}
+		checkpoints, err := git.Checkpoints(".")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+		for _, cp := range checkpoints {
+			fmt.Printf("%s  %s  %s  %s  %s\n", cp.Name, cp.Commit[:min(len(cp.Commit), 10)], cp.Created.Format("2006-01-02 15:04"), cp.Worktree, cp.Subject)
+		}
+		return 0
+	case "diff":
+		cp, err := git.FindCheckpoint(".", name)
+		if err == nil {
+			err = git.DiffCheckpoint(".", cp, args...)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+		return 0
+	case "restore":
+		if len(args) > 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(checkpointsUsage))
+			return 2
+		}
+		cp, err := git.FindCheckpoint(".", name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+		// Keep the state being replaced so the restore can be undone too
+		current, err := git.CreateCheckpoint(".", "Before restoring "+cp.Name)
+		if err == nil {
+			err = git.RestoreCheckpoint(".", cp)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+		fmt.Printf("Restored checkpoint %s; the previous state is checkpoint %s\n", cp.Name, current.Name)
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(checkpointsUsage))
+	return 2
+}
+
+// recordCheckpoint records a checkpoint of the repository containing dir
+// before a launch. Outside a git repository there is nothing to record.
+func recordCheckpoint(dir string) error {
+	if _, err := git.Root(dir); err != nil {
+		return nil
+	}
+	cp, err := git.CreateCheckpoint(dir, "Before launching Claude Code")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "📌 Checkpoint %s recorded (undo with: cc-launcher checkpoints restore %s)\n", cp.Name, cp.Name)
+	return nil
+}
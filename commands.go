@@ -0,0 +1,220 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/ui"
+)
+
+// jsonSchemaVersion is bumped whenever a field is removed or changes meaning
+// in the output of `list --json` or `inspect --json`. Adding fields does not
+// change the version.
+const jsonSchemaVersion = 1
+
+// listOutput is the document printed by `cc-launcher list --json`
+type listOutput struct {
+	Version int        `json:"version"`
+	Configs []mcpEntry `json:"configs"`
+}
+
+// mcpEntry describes a single discovered MCP configuration file
+type mcpEntry struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Origin   string   `json:"origin"`
+	Servers  []string `json:"servers"`
+	Valid    bool     `json:"valid"`
+	Error    string   `json:"error,omitempty"`
+	Size     int64    `json:"size"`
+	Modified string   `json:"modified,omitempty"`
+}
+
+// inspectOutput is the document printed by `cc-launcher inspect <name> --json`
+type inspectOutput struct {
+	Version int `json:"version"`
+	mcpEntry
+	MCPServers map[string]json.RawMessage `json:"mcpServers,omitempty"`
+}
+
+// subcommands maps subcommand names to their handlers. Each handler receives
+// the arguments following the subcommand name and returns the exit code.
+var subcommands = map[string]func(args []string) int{
+	"list":        runList,
+	"inspect":     runInspect,
+	"completion":  runCompletion,
+	"secrets":     runSecrets,
+	"run":         runPlan,
+	"checkpoints": runCheckpoints,
+	"tmux":        runTmux,
+	"__complete":  runComplete,
+}
+
+// runSubcommand runs the subcommand named by args[0] if there is one.
+// It reports whether a subcommand was found.
+func runSubcommand(args []string) (int, bool) {
+	if len(args) == 0 {
+		return 0, false
+	}
+	handler, ok := subcommands[args[0]]
+	if !ok {
+		return 0, false
+	}
+	return handler(args[1:]), true
+}
+
+func runList(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "Print machine-readable JSON")
+	localFlag := fs.Bool("local", false, "Only list local MCP configurations")
+	fs.Parse(args)
+
+	configs, err := config.DiscoverMCPConfigs(*localFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding MCP files: "+err.Error()))
+		return 1
+	}
+
+	if *jsonFlag {
+		out := listOutput{Version: jsonSchemaVersion, Configs: []mcpEntry{}}
+		for _, c := range configs {
+			out.Configs = append(out.Configs, newMCPEntry(c))
+		}
+		return printJSON(out)
+	}
+
+	if len(configs) == 0 {
+		fmt.Println("No MCP configuration files found in .claude/mcp/ or ~/.claude/mcp/")
+		return 0
+	}
+	for _, c := range configs {
+		if c.Valid {
+			fmt.Printf("%-30s %s [%s]\n", c.Label(), c.Path, strings.Join(c.Servers, ", "))
+		} else {
+			fmt.Printf("%-30s %s (invalid: %s)\n", c.Label(), c.Path, c.Error)
+		}
+	}
+	return 0
+}
+
+func runInspect(args []string) int {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	jsonFlag := fs.Bool("json", false, "Print machine-readable JSON")
+	localFlag := fs.Bool("local", false, "Only search local MCP configurations")
+	fs.Parse(reorderFlags(fs, args))
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Usage: cc-launcher inspect [--json] [--local] <name>"))
+		return 2
+	}
+
+	configs, err := config.DiscoverMCPConfigs(*localFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding MCP files: "+err.Error()))
+		return 1
+	}
+
+	cfg, ok := config.FindMCPConfig(configs, fs.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: no MCP configuration named "+fs.Arg(0)))
+		return 1
+	}
+
+	out := inspectOutput{Version: jsonSchemaVersion, mcpEntry: newMCPEntry(cfg)}
+	if cfg.Valid {
+		// Already parsed once during discovery, so a failure here means the
+		// file changed underneath us; report it like any other invalid file.
+		servers, err := config.ReadMCPServers(cfg.Path)
+		if err != nil {
+			out.Valid = false
+			out.Error = err.Error()
+		}
+		out.MCPServers = servers
+	}
+
+	if *jsonFlag {
+		return printJSON(out)
+	}
+
+	fmt.Printf("Name:    %s\nPath:    %s\nOrigin:  %s\n", out.Name, out.Path, out.Origin)
+	if !out.Valid {
+		fmt.Printf("Invalid: %s\n", out.Error)
+		return 1
+	}
+	for _, name := range out.Servers {
+		fmt.Printf("\n%s:\n  %s\n", name, string(out.MCPServers[name]))
+	}
+	return 0
+}
+
+func newMCPEntry(c config.MCPConfig) mcpEntry {
+	entry := mcpEntry{
+		Name:    c.Name,
+		Path:    c.Path,
+		Origin:  c.Origin,
+		Servers: c.Servers,
+		Valid:   c.Valid,
+		Error:   c.Error,
+		Size:    c.Size,
+	}
+	// Report absolute paths so callers need not know our working directory
+	if abs, err := filepath.Abs(c.Path); err == nil {
+		entry.Path = abs
+	}
+	if entry.Servers == nil {
+		entry.Servers = []string{}
+	}
+	if !c.ModTime.IsZero() {
+		entry.Modified = c.ModTime.UTC().Format(time.RFC3339)
+	}
+	return entry
+}
+
+func printJSON(v any) int {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error encoding JSON: "+err.Error()))
+		return 1
+	}
+	return 0
+}
+
+// reorderFlags moves flags in front of positional arguments so that
+// `inspect context7 --json` works as well as `inspect --json context7`.
+// The standard flag package stops parsing at the first positional argument.
+func reorderFlags(fs *flag.FlagSet, args []string) []string {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			positional = append(positional, args[i+1:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positional = append(positional, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		name := strings.TrimLeft(arg, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		// Non-boolean flags consume the following argument as their value
+		if f := fs.Lookup(name); f != nil {
+			if bf, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !bf.IsBoolFlag() {
+				if i+1 < len(args) {
+					i++
+					flags = append(flags, args[i])
+				}
+			}
+		}
+	}
+	return append(flags, positional...)
+}
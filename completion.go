@@ -0,0 +1,307 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/git"
+	"cc-launcher/internal/ui"
+)
+
+// subcommandFlags lists the flags accepted by each subcommand for completion
+var subcommandFlags = map[string][]string{
+	"list":        {"--json", "--local"},
+	"inspect":     {"--json", "--local"},
+	"completion":  {},
+	"secrets":     {},
+	"run":         {"--dry-run", "--executor"},
+	"checkpoints": {},
+	"tmux":        {},
+}
+
+// valueFlags maps flags that take a value to the __complete kind that lists
+// candidate values for them
+var valueFlags = map[string]string{
+	"--account":         "account",
+	"--mcp":             "mcp",
+	"--permission-mode": "permission-mode",
+	"--preset":          "preset",
+	"--prompt":          "prompt",
+	"--provider":        "provider",
+	"--settings":        "settings",
+	"--target":          "target",
+	"--worktree":        "branch",
+}
+
+func runCompletion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Usage: cc-launcher completion bash|zsh|fish"))
+		return 2
+	}
+
+	switch args[0] {
+	case "bash":
+		writeBashCompletion(os.Stdout)
+	case "zsh":
+		writeZshCompletion(os.Stdout)
+	case "fish":
+		writeFishCompletion(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: unsupported shell "+args[0]+" (expected bash, zsh or fish)"))
+		return 2
+	}
+	return 0
+}
+
+// runComplete is the hidden callback used by the completion scripts. It
+// prints one candidate per line for the requested kind of value, resolved
+// against the current directory. Errors are swallowed so that a broken
+// config file never produces noise in the middle of the user's command line.
+func runComplete(args []string) int {
+	if len(args) != 1 {
+		return 2
+	}
+
+	var candidates []string
+	switch args[0] {
+	case "mcp":
+		configs, err := config.DiscoverMCPConfigs(false)
+		if err != nil {
+			return 1
+		}
+		candidates = config.QualifiedNames(configs)
+	case "prompt":
+		fragments, err := config.DiscoverPrompts(false)
+		if err != nil {
+			return 1
+		}
+		candidates = config.QualifiedNames(fragments)
+	case "settings":
+		variants, err := config.DiscoverSettingsVariants(false)
+		if err != nil {
+			return 1
+		}
+		candidates = config.QualifiedNames(variants)
+	case "preset":
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return 1
+		}
+		candidates = settings.PresetNames()
+	case "target":
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return 1
+		}
+		for _, t := range settings.LaunchTargets() {
+			candidates = append(candidates, t.Name)
+		}
+	case "account":
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return 1
+		}
+		candidates = settings.AccountNames()
+	case "permission-mode":
+		candidates = append(slices.Clone(config.PermissionModes), "bypass")
+	case "branch":
+		branches, err := git.Branches(".")
+		if err != nil {
+			return 1
+		}
+		candidates = branches
+	case "provider":
+		settings, err := config.LoadSettings()
+		if err != nil {
+			return 1
+		}
+		providers, err := config.LoadProviders(settings.ProjectProviderTokens)
+		if err != nil {
+			return 1
+		}
+		for _, p := range providers {
+			candidates = append(candidates, p.Name)
+		}
+	default:
+		return 2
+	}
+
+	for _, c := range candidates {
+		fmt.Println(c)
+	}
+	return 0
+}
+
+// subcommandNames returns the public subcommands in sorted order
+func subcommandNames() []string {
+	var names []string
+	for name := range subcommandFlags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// launcherFlagNames returns the top-level flags as they are typed on the
+// command line, e.g. "-r" and "--resume"
+func launcherFlagNames() []string {
+	fs := flag.NewFlagSet("cc-launcher", flag.ContinueOnError)
+	registerFlags(fs)
+
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) == 1 {
+			names = append(names, "-"+f.Name)
+		} else {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	return names
+}
+
+func writeBashCompletion(w io.Writer) {
+	fmt.Fprintf(w, `# bash completion for cc-launcher
+# Install with: source <(cc-launcher completion bash)
+_cc_launcher() {
+    local cur prev cmd
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    cmd="${COMP_WORDS[0]}"
+
+    case "$prev" in
+`)
+	for _, name := range sortedKeys(valueFlags) {
+		fmt.Fprintf(w, "        %s)\n            COMPREPLY=( $(compgen -W \"$(\"$cmd\" __complete %s 2>/dev/null)\" -- \"$cur\") )\n            return ;;\n", name, valueFlags[name])
+	}
+	fmt.Fprintf(w, `    esac
+
+    if [[ $COMP_CWORD -gt 1 ]]; then
+        case "${COMP_WORDS[1]}" in
+`)
+	for _, sub := range subcommandNames() {
+		fmt.Fprintf(w, "            %s)\n", sub)
+		switch sub {
+		case "inspect":
+			fmt.Fprintf(w, "                COMPREPLY=( $(compgen -W \"%s $(\"$cmd\" __complete mcp 2>/dev/null)\" -- \"$cur\") )\n", strings.Join(subcommandFlags[sub], " "))
+		case "completion":
+			fmt.Fprintf(w, "                COMPREPLY=( $(compgen -W \"bash zsh fish\" -- \"$cur\") )\n")
+		default:
+			fmt.Fprintf(w, "                COMPREPLY=( $(compgen -W \"%s\" -- \"$cur\") )\n", strings.Join(subcommandFlags[sub], " "))
+		}
+		fmt.Fprintf(w, "                return ;;\n")
+	}
+	fmt.Fprintf(w, `        esac
+    fi
+
+    if [[ $COMP_CWORD -eq 1 && "$cur" != -* ]]; then
+        COMPREPLY=( $(compgen -W "%s" -- "$cur") )
+        return
+    fi
+    COMPREPLY=( $(compgen -W "%s" -- "$cur") )
+}
+complete -F _cc_launcher cc-launcher
+`, strings.Join(subcommandNames(), " "), strings.Join(launcherFlagNames(), " "))
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, `#compdef cc-launcher
+# zsh completion for cc-launcher
+# Install with: source <(cc-launcher completion zsh)
+_cc_launcher() {
+    local cmd=$words[1]
+    local -a values
+
+    case $words[CURRENT-1] in
+`)
+	for _, name := range sortedKeys(valueFlags) {
+		fmt.Fprintf(w, "        %s)\n            values=(${(f)\"$($cmd __complete %s 2>/dev/null)\"})\n            compadd -a values\n            return ;;\n", name, valueFlags[name])
+	}
+	fmt.Fprintf(w, `    esac
+
+    if (( CURRENT > 2 )); then
+        case $words[2] in
+`)
+	for _, sub := range subcommandNames() {
+		fmt.Fprintf(w, "            %s)\n", sub)
+		switch sub {
+		case "inspect":
+			fmt.Fprintf(w, "                values=(%s ${(f)\"$($cmd __complete mcp 2>/dev/null)\"})\n", strings.Join(subcommandFlags[sub], " "))
+		case "completion":
+			fmt.Fprintf(w, "                values=(bash zsh fish)\n")
+		default:
+			fmt.Fprintf(w, "                values=(%s)\n", strings.Join(subcommandFlags[sub], " "))
+		}
+		fmt.Fprintf(w, "                compadd -a values\n                return ;;\n")
+	}
+	fmt.Fprintf(w, `        esac
+    fi
+
+    if (( CURRENT == 2 )) && [[ $PREFIX != -* ]]; then
+        values=(%s)
+    else
+        values=(%s)
+    fi
+    compadd -a values
+}
+compdef _cc_launcher cc-launcher
+`, strings.Join(subcommandNames(), " "), strings.Join(launcherFlagNames(), " "))
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for cc-launcher\n")
+	fmt.Fprintf(w, "# Install with: cc-launcher completion fish > ~/.config/fish/completions/cc-launcher.fish\n")
+	fmt.Fprintf(w, "complete -c cc-launcher -f\n")
+
+	noSub := "not __fish_seen_subcommand_from " + strings.Join(subcommandNames(), " ")
+	for _, sub := range subcommandNames() {
+		fmt.Fprintf(w, "complete -c cc-launcher -n '__fish_use_subcommand' -a %s\n", sub)
+	}
+
+	for _, name := range launcherFlagNames() {
+		kind, takesValue := valueFlags[name]
+		opt := "-l " + strings.TrimPrefix(name, "--")
+		if !strings.HasPrefix(name, "--") {
+			opt = "-s " + strings.TrimPrefix(name, "-")
+		}
+		if takesValue {
+			fmt.Fprintf(w, "complete -c cc-launcher -n '%s' %s -x -a '(%s)'\n", noSub, opt, fishCallback(kind))
+		} else {
+			fmt.Fprintf(w, "complete -c cc-launcher -n '%s' %s\n", noSub, opt)
+		}
+	}
+
+	for _, sub := range subcommandNames() {
+		cond := "__fish_seen_subcommand_from " + sub
+		for _, name := range subcommandFlags[sub] {
+			fmt.Fprintf(w, "complete -c cc-launcher -n '%s' -l %s\n", cond, strings.TrimPrefix(name, "--"))
+		}
+		switch sub {
+		case "inspect":
+			fmt.Fprintf(w, "complete -c cc-launcher -n '%s' -a '(%s)'\n", cond, fishCallback("mcp"))
+		case "completion":
+			fmt.Fprintf(w, "complete -c cc-launcher -n '%s' -a 'bash zsh fish'\n", cond)
+		}
+	}
+}
+
+// fishCallback returns the command that asks the binary for candidate
+// values. fish does not allow a command substitution in command position,
+// so unlike bash and zsh this calls cc-launcher from PATH.
+func fishCallback(kind string) string {
+	return "cc-launcher __complete " + kind + " 2>/dev/null"
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+package claudecli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"cc-launcher/internal/config"
+)
+
+// probeTimeout bounds each run of the claude binary while probing it
+const probeTimeout = 10 * time.Second
+
+// versionPattern extracts the version number from `claude --version`
+var versionPattern = regexp.MustCompile(`\d+\.\d+\.\d+\S*`)
+
+// Info describes what an installed claude binary supports
+type Info struct {
+	Path    string    `json:"-"`
+	ModTime time.Time `json:"modTime"`
+	Version string    `json:"version,omitempty"`
+	// Flags lists the long options found in `claude --help`. It is empty
+	// when the help output could not be parsed, in which case every option
+	// is assumed to be supported.
+	Flags []string `json:"flags,omitempty"`
+}
+
+// Supports reports whether the binary accepts the long option flag
+func (i Info) Supports(flag string) bool {
+	return len(i.Flags) == 0 || slices.Contains(i.Flags, flag)
+}
+
+// Unsupported returns the flags the binary does not accept
+func (i Info) Unsupported(flags []string) []string {
+	var unsupported []string
+	for _, flag := range flags {
+		if !i.Supports(flag) {
+			unsupported = append(unsupported, flag)
+		}
+	}
+	return unsupported
+}
+
+// Explain describes why flag is unavailable, for display next to a
+// disabled option
+func (i Info) Explain(flag string) string {
+	version := i.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	return fmt.Sprintf("%s is not supported by claude %s", flag, version)
+}
+
+// Detect probes the claude binary at path with --version and --help. Results
+// are cached in ~/.claude/launcher/cli-cache.json, keyed by the binary's
+// path and modification time, so the binary only runs again after it changed.
+func Detect(path string) (Info, error) {
+	// Resolve symlinks so that an upgrade replacing the link target is
+	// noticed through the target's modification time
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	cache := loadCache()
+	if info, ok := cache[path]; ok && info.ModTime.Equal(stat.ModTime()) {
+		info.Path = path
+		return info, nil
+	}
+
+	info := Info{Path: path, ModTime: stat.ModTime()}
+	version, err := run(path, "--version")
+	if err != nil {
+		return Info{}, err
+	}
+	info.Version = versionPattern.FindString(version)
+	help, err := run(path, "--help")
+	if err != nil {
+		return Info{}, err
+	}
+	info.Flags = parseFlags(help)
+
+	cache[path] = info
+	saveCache(cache)
+	return info, nil
+}
+
+// parseFlags returns the long options listed in help output. Only option
+// lines are considered, so flags mentioned in descriptions are ignored.
+func parseFlags(help string) []string {
+	var flags []string
+	scanner := bufio.NewScanner(strings.NewReader(help))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "-") {
+			continue
+		}
+		// The option names end where the description starts
+		names, _, _ := strings.Cut(line, "  ")
+		for _, field := range strings.Fields(names) {
+			field = strings.TrimRight(field, ",")
+			if strings.HasPrefix(field, "--") && !slices.Contains(flags, field) {
+				flags = append(flags, field)
+			}
+		}
+	}
+	return flags
+}
+
+func run(path string, arg string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, arg).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to run %s %s: %w", path, arg, err)
+	}
+	return string(out), nil
+}
+
+func cachePath() (string, error) {
+	dir, err := config.LauncherDir(true)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cli-cache.json"), nil
+}
+
+// loadCache reads the probe cache. A missing or unreadable cache is empty.
+func loadCache() map[string]Info {
+	cache := map[string]Info{}
+	path, err := cachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return map[string]Info{}
+	}
+	return cache
+}
+
+// saveCache writes the probe cache, ignoring errors since it is only an
+// optimization
+func saveCache(cache map[string]Info) {
+	path, err := cachePath()
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	os.WriteFile(path, append(data, '\n'), 0o644)
+}
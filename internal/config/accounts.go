@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Account is a Claude login kept apart from others through its own
+// CLAUDE_CONFIG_DIR. Accounts are read from the "accounts" object in
+// config.json, keyed by name.
+type Account struct {
+	Name string `json:"-"`
+	// ConfigDir is exported as CLAUDE_CONFIG_DIR. A leading ~ is expanded.
+	ConfigDir string `json:"configDir"`
+	// Env holds additional environment variables for Claude Code
+	Env map[string]string `json:"env,omitempty"`
+	// Paths are project directories whose sessions use this account by
+	// default, including their subdirectories. A leading ~ is expanded.
+	Paths []string `json:"paths,omitempty"`
+}
+
+// AccountNames returns the names of all accounts in sorted order
+func (s Settings) AccountNames() []string {
+	names := make([]string, 0, len(s.Accounts))
+	for name := range s.Accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FindAccount returns the account with the given name
+func (s Settings) FindAccount(name string) (Account, bool) {
+	a, ok := s.Accounts[name]
+	if !ok {
+		return Account{}, false
+	}
+	a.Name = name
+	return a, true
+}
+
+// AccountForDir returns the account whose Paths contain dir. When several
+// match, the most specific path wins.
+func (s Settings) AccountForDir(dir string) (Account, bool) {
+	var best Account
+	bestLen := -1
+	for _, name := range s.AccountNames() {
+		a := s.Accounts[name]
+		for _, p := range a.Paths {
+			root := filepath.Clean(ExpandHome(p))
+			if !isWithin(dir, root) || len(root) <= bestLen {
+				continue
+			}
+			best, bestLen = a, len(root)
+			best.Name = name
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// LaunchEnv returns the environment variables selecting the account
+func (a Account) LaunchEnv() (map[string]string, error) {
+	if a.ConfigDir == "" {
+		return nil, fmt.Errorf("account %s has no configDir", a.Name)
+	}
+	env := make(map[string]string, len(a.Env)+1)
+	for name, value := range a.Env {
+		env[name] = value
+	}
+	env["CLAUDE_CONFIG_DIR"] = ExpandHome(a.ConfigDir)
+	return env, nil
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(homeDir, path[1:])
+}
+
+// isWithin reports whether path is root or lies below it
+func isWithin(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ResolveAddDir expands a leading ~ in an additional directory, makes it
+// absolute from the working directory and checks that it is a directory.
+// The absolute path is returned even when the check fails.
+func ResolveAddDir(path string) (string, error) {
+	abs, err := filepath.Abs(ExpandHome(path))
+	if err != nil {
+		return path, fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	switch {
+	case os.IsNotExist(err):
+		return abs, fmt.Errorf("%s does not exist", path)
+	case err != nil:
+		return abs, fmt.Errorf("failed to check %s: %w", path, err)
+	case !info.IsDir():
+		return abs, fmt.Errorf("%s is not a directory", path)
+	}
+	return abs, nil
+}
+
+// ResolveAddDirs resolves paths with ResolveAddDir, dropping duplicates,
+// and stops at the first that is not a directory
+func ResolveAddDirs(paths []string) ([]string, error) {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, path := range paths {
+		dir, err := ResolveAddDir(path)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs, nil
+}
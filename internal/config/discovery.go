@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Origin values describe where a discovered file was found
+const (
+	OriginLocal  = "local"
+	OriginGlobal = "global"
+)
+
+// discoveredFile is implemented by the files found by discover, such as
+// MCPConfig, PromptFragment and SettingsVariant
+type discoveredFile interface {
+	// file returns the file's name, origin and path
+	file() (name, origin, path string)
+}
+
+// discover scans the directory dir below .claude and, unless localOnly is
+// set, below ~/.claude for files matching pattern and loads each with load,
+// which may leave a file out by returning false. Local files are always
+// listed before global ones.
+func discover[T any](dir, pattern string, localOnly bool, load func(path, origin string) (T, bool, error)) ([]T, error) {
+	dirs := []string{filepath.Join(".claude", dir)}
+	origins := []string{OriginLocal}
+	if !localOnly {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		dirs = append(dirs, filepath.Join(homeDir, ".claude", dir))
+		origins = append(origins, OriginGlobal)
+	}
+
+	var found []T
+	for i, dir := range dirs {
+		files, err := scanDirectory(dir, pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan %s directory %s: %w", origins[i], dir, err)
+		}
+		for _, file := range files {
+			item, ok, err := load(file, origins[i])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				found = append(found, item)
+			}
+		}
+	}
+	return found, nil
+}
+
+// scanDirectory returns the files in dir matching pattern.
+// It returns an empty slice if the directory doesn't exist or is inaccessible,
+// logging appropriate warnings only when debug mode is enabled. Returns an error only for unexpected glob failures.
+func scanDirectory(dir, pattern string) ([]string, error) {
+	// Check if directory exists and is accessible
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			// Directory doesn't exist - this is normal, not an error
+			return []string{}, nil
+		}
+		// Directory exists but is not accessible
+		if debugMode {
+			log.Printf("Warning: directory %s exists but is not accessible: %v", dir, err)
+		}
+		return []string{}, nil
+	}
+
+	// Directory exists and is accessible, scan for matching files
+	files, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		// Glob operation failed unexpectedly
+		return nil, fmt.Errorf("failed to glob pattern %s: %w", filepath.Join(dir, pattern), err)
+	}
+
+	if debugMode {
+		if len(files) == 0 {
+			log.Printf("Info: No files matching %s found in %s", pattern, dir)
+		} else {
+			log.Printf("Info: Found %d file(s) matching %s in %s", len(files), pattern, dir)
+		}
+	}
+
+	return files, nil
+}
+
+// findDiscovered looks up a discovered file by name or path. A name may be
+// qualified with its origin ("global:context7") to pick between local and
+// global files sharing a name; otherwise local wins.
+func findDiscovered[T discoveredFile](files []T, name string) (T, bool) {
+	origin := ""
+	if prefix, rest, ok := strings.Cut(name, ":"); ok && (prefix == OriginLocal || prefix == OriginGlobal) {
+		origin, name = prefix, rest
+	}
+	for _, f := range files {
+		fileName, fileOrigin, path := f.file()
+		if origin != "" && fileOrigin != origin {
+			continue
+		}
+		if fileName == name || path == name {
+			return f, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// QualifiedNames returns the names the discovered files are found by.
+// Files shadowed by an earlier one of the same name, which the plain name
+// does not find, are qualified with their origin ("global:<name>").
+func QualifiedNames[T discoveredFile](files []T) []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		name, origin, _ := f.file()
+		if seen[name] {
+			names = append(names, origin+":"+name)
+		} else {
+			names = append(names, name)
+		}
+		seen[name] = true
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeDiscoveryFile writes a file below the .claude directory in root
+func writeDiscoveryFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, ".claude", name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoveredFilesShadowing(t *testing.T) {
+	home, dir := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(dir)
+	writeDiscoveryFile(t, dir, "prompts/reviewer.md", "local reviewer")
+	writeDiscoveryFile(t, home, "prompts/reviewer.md", "global reviewer")
+	writeDiscoveryFile(t, home, "prompts/terse.md", "terse")
+	writeDiscoveryFile(t, dir, "settings.review.json", "{}")
+	writeDiscoveryFile(t, dir, "settings.local.json", "{}")
+	writeDiscoveryFile(t, home, "settings.review.json", "{}")
+	writeDiscoveryFile(t, dir, "mcp/context7.json", `{"mcpServers":{}}`)
+	writeDiscoveryFile(t, home, "mcp/context7.json", `{"mcpServers":{}}`)
+
+	prompts, err := DiscoverPrompts(false)
+	if err != nil {
+		t.Fatalf("DiscoverPrompts: %v", err)
+	}
+	if got, want := QualifiedNames(prompts), []string{"reviewer", "global:reviewer", "terse"}; !slices.Equal(got, want) {
+		t.Errorf("prompt names = %q, want %q", got, want)
+	}
+	if p, ok := FindPrompt(prompts, "reviewer"); !ok || p.Text != "local reviewer" {
+		t.Errorf("FindPrompt(reviewer) = %+v, want the local one", p)
+	}
+	if p, ok := FindPrompt(prompts, "global:reviewer"); !ok || p.Text != "global reviewer" {
+		t.Errorf("FindPrompt(global:reviewer) = %+v, want the global one", p)
+	}
+	if _, ok := FindPrompt(prompts, "local:terse"); ok {
+		t.Error("FindPrompt(local:terse) found the global fragment")
+	}
+
+	variants, err := DiscoverSettingsVariants(false)
+	if err != nil {
+		t.Fatalf("DiscoverSettingsVariants: %v", err)
+	}
+	if got, want := QualifiedNames(variants), []string{"review", "global:review"}; !slices.Equal(got, want) {
+		t.Errorf("settings names = %q, want %q", got, want)
+	}
+	if v, ok := FindSettingsVariant(variants, variants[1].Path); !ok || v.Origin != OriginGlobal {
+		t.Errorf("FindSettingsVariant by path = %+v, want the global variant", v)
+	}
+
+	configs, err := DiscoverMCPConfigs(true)
+	if err != nil {
+		t.Fatalf("DiscoverMCPConfigs: %v", err)
+	}
+	if got, want := QualifiedNames(configs), []string{"context7"}; !slices.Equal(got, want) {
+		t.Errorf("local MCP names = %q, want %q", got, want)
+	}
+}
@@ -1,88 +1,121 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 )
 
 var debugMode bool
 
+// MCPConfig describes a discovered MCP configuration file together with the
+// servers it defines and whether it could be parsed.
+type MCPConfig struct {
+	Path    string
+	Name    string
+	Origin  string
+	Servers []string
+	Valid   bool
+	Error   string
+	Size    int64
+	ModTime time.Time
+}
+
+func (c MCPConfig) file() (string, string, string) {
+	return c.Name, c.Origin, c.Path
+}
+
+// Label returns the display label used by the TUI, e.g. "context7 (local)"
+func (c MCPConfig) Label() string {
+	return fmt.Sprintf("%s (%s)", c.Name, c.Origin)
+}
+
 // SetDebugMode enables or disables debug logging
 func SetDebugMode(enabled bool) {
 	debugMode = enabled
 }
 
-// FindMCPFiles discovers MCP configuration files in local and optionally global directories.
-// It returns a slice of file paths to *.json files found in:
+// DiscoverMCPConfigs discovers the *.json MCP configuration files in:
 // - .claude/mcp/ (local directory)
 // - ~/.claude/mcp/ (global directory, unless localOnly is true)
 //
-// If localOnly is true, only the local directory is scanned.
-// Returns an error if there are issues accessing the user's home directory
-// or if glob operations fail unexpectedly.
-func FindMCPFiles(localOnly bool) ([]string, error) {
-	var mcpFiles []string
+// and parses each file to report its origin, the servers it defines and
+// whether it is valid. Local files are always listed before global ones.
+func DiscoverMCPConfigs(localOnly bool) ([]MCPConfig, error) {
+	return discover("mcp", "*.json", localOnly, func(path, origin string) (MCPConfig, bool, error) {
+		return loadMCPConfig(path, origin), true, nil
+	})
+}
 
-	// Scan local directory (.claude/mcp)
-	localDir := ".claude/mcp"
-	files, err := scanMCPDirectory(localDir)
-	if err != nil {
-		return nil, fmt.Errorf("failed to scan local MCP directory %s: %w", localDir, err)
+// MCPPaths returns the file paths of the given configurations in order
+func MCPPaths(configs []MCPConfig) []string {
+	paths := make([]string, 0, len(configs))
+	for _, c := range configs {
+		paths = append(paths, c.Path)
 	}
-	mcpFiles = append(mcpFiles, files...)
+	return paths
+}
 
-	// Scan global directory (~/.claude/mcp) unless localOnly is true
-	if !localOnly {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get user home directory: %w", err)
-		}
+// FindMCPConfig looks up a discovered configuration by name or path.
+// A name may be qualified with its origin ("global:context7") to pick
+// between local and global files sharing a name; otherwise local wins.
+func FindMCPConfig(configs []MCPConfig, name string) (MCPConfig, bool) {
+	return findDiscovered(configs, name)
+}
 
-		globalDir := filepath.Join(homeDir, ".claude", "mcp")
-		files, err = scanMCPDirectory(globalDir)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan global MCP directory %s: %w", globalDir, err)
-		}
-		mcpFiles = append(mcpFiles, files...)
+// ReadMCPServers parses an MCP configuration file and returns the raw
+// definition of each server keyed by server name.
+func ReadMCPServers(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
 	}
 
-	return mcpFiles, nil
+	var doc struct {
+		MCPServers map[string]json.RawMessage `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if doc.MCPServers == nil {
+		return nil, fmt.Errorf("%s has no \"mcpServers\" object", path)
+	}
+	return doc.MCPServers, nil
 }
 
-// scanMCPDirectory scans a specific directory for *.json files.
-// It returns an empty slice if the directory doesn't exist or is inaccessible,
-// logging appropriate warnings only when debug mode is enabled. Returns an error only for unexpected glob failures.
-func scanMCPDirectory(dir string) ([]string, error) {
-	// Check if directory exists and is accessible
-	if _, err := os.Stat(dir); err != nil {
-		if os.IsNotExist(err) {
-			// Directory doesn't exist - this is normal, not an error
-			return []string{}, nil
-		}
-		// Directory exists but is not accessible
-		if debugMode {
-			log.Printf("Warning: MCP directory %s exists but is not accessible: %v", dir, err)
-		}
-		return []string{}, nil
+// loadMCPConfig builds the MCPConfig for a single file. Parse failures are
+// recorded on the result instead of being returned so that one broken file
+// does not hide the others.
+func loadMCPConfig(path string, origin string) MCPConfig {
+	cfg := MCPConfig{
+		Path:   path,
+		Name:   strings.TrimSuffix(filepath.Base(path), ".json"),
+		Origin: origin,
 	}
 
-	// Directory exists and is accessible, scan for JSON files
-	pattern := filepath.Join(dir, "*.json")
-	files, err := filepath.Glob(pattern)
-	if err != nil {
-		// Glob operation failed unexpectedly
-		return nil, fmt.Errorf("failed to glob pattern %s: %w", pattern, err)
+	if info, err := os.Stat(path); err == nil {
+		cfg.Size = info.Size()
+		cfg.ModTime = info.ModTime()
 	}
 
-	if debugMode {
-		if len(files) == 0 {
-			log.Printf("Info: No MCP configuration files found in %s", dir)
-		} else {
-			log.Printf("Info: Found %d MCP configuration file(s) in %s", len(files), dir)
+	servers, err := ReadMCPServers(path)
+	if err != nil {
+		cfg.Error = err.Error()
+		if debugMode {
+			log.Printf("Warning: %v", err)
 		}
+		return cfg
 	}
 
-	return files, nil
-}
\ No newline at end of file
+	cfg.Valid = true
+	for name := range servers {
+		cfg.Servers = append(cfg.Servers, name)
+	}
+	sort.Strings(cfg.Servers)
+	return cfg
+}
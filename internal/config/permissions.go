@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BypassPermissions is the permission mode that never asks, like yolo
+const BypassPermissions = "bypassPermissions"
+
+// PermissionModes are the values Claude Code accepts for --permission-mode
+var PermissionModes = []string{"default", "plan", "acceptEdits", BypassPermissions}
+
+// PermissionMode checks a permission mode name, accepting "bypass" for
+// bypassPermissions, and returns it as Claude Code expects it
+func PermissionMode(name string) (string, error) {
+	if strings.EqualFold(name, "bypass") {
+		return BypassPermissions, nil
+	}
+	for _, mode := range PermissionModes {
+		if strings.EqualFold(name, mode) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown permission mode %q (use %s)", name, strings.Join(PermissionModes, ", "))
+}
+
+// SkipPermissionsFlag returns the first of args, as passed to Claude Code,
+// that turns off its permission checks: --dangerously-skip-permissions or
+// --permission-mode with bypassPermissions. It returns "" when there is
+// none.
+func SkipPermissionsFlag(args []string) string {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(arg, "=")
+		switch name {
+		case "--dangerously-skip-permissions":
+			return arg
+		case "--permission-mode":
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+			}
+			if mode, err := PermissionMode(value); err == nil && mode == BypassPermissions {
+				return name + " " + value
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestSkipPermissionsFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"--verbose", "--model", "opus"}, ""},
+		{[]string{"--dangerously-skip-permissions"}, "--dangerously-skip-permissions"},
+		{[]string{"--verbose", "--dangerously-skip-permissions=true"}, "--dangerously-skip-permissions=true"},
+		{[]string{"--permission-mode", "bypassPermissions"}, "--permission-mode bypassPermissions"},
+		{[]string{"--permission-mode=bypassPermissions"}, "--permission-mode bypassPermissions"},
+		{[]string{"--permission-mode", "bypass"}, "--permission-mode bypass"},
+		{[]string{"--permission-mode", "plan"}, ""},
+		{[]string{"--permission-mode=acceptEdits"}, ""},
+		{[]string{"--permission-mode"}, ""},
+	}
+	for _, tt := range tests {
+		if got := SkipPermissionsFlag(tt.args); got != tt.want {
+			t.Errorf("SkipPermissionsFlag(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
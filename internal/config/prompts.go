@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// PromptFragment is a Markdown file of system prompt text, such as a
+// persona, discovered in .claude/prompts or ~/.claude/prompts
+type PromptFragment struct {
+	Path   string
+	Name   string
+	Origin string
+	Text   string
+	Error  string
+}
+
+func (p PromptFragment) file() (string, string, string) {
+	return p.Name, p.Origin, p.Path
+}
+
+// Label returns the display label used by the TUI, e.g. "reviewer (local)"
+func (p PromptFragment) Label() string {
+	return fmt.Sprintf("%s (%s)", p.Name, p.Origin)
+}
+
+// DiscoverPrompts reads the fragments in .claude/prompts and, unless
+// localOnly is set, ~/.claude/prompts. Local fragments are listed first.
+func DiscoverPrompts(localOnly bool) ([]PromptFragment, error) {
+	return discover("prompts", "*.md", localOnly, func(path, origin string) (PromptFragment, bool, error) {
+		p := PromptFragment{Path: path, Name: strings.TrimSuffix(filepath.Base(path), ".md"), Origin: origin}
+		data, err := os.ReadFile(path)
+		switch {
+		case err != nil:
+			p.Error = err.Error()
+		case !utf8.Valid(data):
+			p.Error = "not UTF-8 text"
+		default:
+			p.Text = strings.TrimSpace(string(data))
+		}
+		return p, true, nil
+	})
+}
+
+// FindPrompt looks up a discovered fragment by name or path. Like
+// FindMCPConfig, a name may be qualified with its origin ("global:reviewer").
+func FindPrompt(fragments []PromptFragment, name string) (PromptFragment, bool) {
+	return findDiscovered(fragments, name)
+}
+
+// SelectedPrompts returns the fragments whose indexes are in selected, in
+// order
+func SelectedPrompts(fragments []PromptFragment, selected map[int]struct{}) []PromptFragment {
+	var chosen []PromptFragment
+	for i, p := range fragments {
+		if _, ok := selected[i]; ok {
+			chosen = append(chosen, p)
+		}
+	}
+	return chosen
+}
+
+// JoinPrompts concatenates the text of fragments, separated by blank lines
+func JoinPrompts(fragments []PromptFragment) string {
+	texts := make([]string, 0, len(fragments))
+	for _, p := range fragments {
+		if p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// EstimateTokens roughly estimates the number of tokens in text, assuming
+// four characters per token
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
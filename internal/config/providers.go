@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+)
+
+// Provider is an API endpoint profile, such as a gateway exposing the
+// Anthropic API. Providers are read from providers.json in the user and
+// project launcher directories, keyed by name.
+type Provider struct {
+	Name string `json:"-"`
+	// BaseURL is exported to Claude Code as ANTHROPIC_BASE_URL
+	BaseURL string `json:"baseUrl"`
+	// AuthTokenEnv names the environment variable holding the API token,
+	// which is exported to Claude Code as ANTHROPIC_AUTH_TOKEN
+	AuthTokenEnv string `json:"authTokenEnv,omitempty"`
+	// AuthTokenSecret names a secret in the encrypted credential store to
+	// use as the token. It takes precedence over AuthTokenEnv.
+	AuthTokenSecret string `json:"authTokenSecret,omitempty"`
+	// Env holds additional environment variables for Claude Code
+	Env map[string]string `json:"env,omitempty"`
+	// Models replaces the model picker list while the provider is selected
+	Models []string `json:"models,omitempty"`
+	// ModelEnv maps Claude Code's model aliases to provider models, e.g.
+	// ANTHROPIC_DEFAULT_SONNET_MODEL
+	ModelEnv map[string]string `json:"modelEnv,omitempty"`
+	// HealthPath is requested relative to BaseURL by the pre-flight check
+	// (default /v1/models)
+	HealthPath string `json:"healthPath,omitempty"`
+}
+
+// builtinProviders are available without any configuration. A profile with
+// the same name in providers.json replaces the built-in one.
+var builtinProviders = map[string]Provider{
+	"zai": {
+		BaseURL:      "https://api.z.ai/api/anthropic",
+		AuthTokenEnv: "Z_AI_API_KEY",
+	},
+}
+
+// Anthropic stands for talking to Anthropic directly. It is not listed by
+// LoadProviders but FindProvider resolves it so that failover chains can
+// fall back to it. It carries no token; Claude Code uses its own login.
+var Anthropic = Provider{Name: "anthropic", BaseURL: "https://api.anthropic.com"}
+
+// LoadProviders returns the built-in providers merged with the user and
+// project providers.json files, sorted by name. A project file may not
+// change where an existing provider sends its token, or which token and
+// environment it uses. The providers it adds may only use the tokens in
+// allowedTokens, given as "env:NAME" or "secret:NAME" (see TokenRef), so
+// that a cloned repository cannot send the user's tokens elsewhere.
+func LoadProviders(allowedTokens []string) ([]Provider, error) {
+	merged := make(map[string]Provider, len(builtinProviders))
+	for name, p := range builtinProviders {
+		merged[name] = p
+	}
+
+	layers, err := loadLayers[map[string]Provider]("providers.json")
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range layers {
+		for name, p := range layer.value {
+			existing, ok := merged[name]
+			switch {
+			case layer.global:
+			case ok:
+				if field := credentialChange(existing, p); field != "" {
+					return nil, fmt.Errorf("the project's providers.json changes the %s of provider %s; give the project's provider another name", field, name)
+				}
+			case p.TokenRef() != "" && !slices.Contains(allowedTokens, p.TokenRef()):
+				return nil, fmt.Errorf("the project's providers.json gives provider %s the token %s; list it in projectProviderTokens in your own config.json to allow that", name, p.TokenRef())
+			}
+			merged[name] = p
+		}
+	}
+
+	providers := make([]Provider, 0, len(merged))
+	for name, p := range merged {
+		p.Name = name
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers, nil
+}
+
+// FindProvider returns the provider with the given name. "anthropic"
+// resolves to Anthropic unless a profile of that name exists.
+func FindProvider(providers []Provider, name string) (Provider, bool) {
+	for _, p := range providers {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	if name == Anthropic.Name {
+		return Anthropic, true
+	}
+	return Provider{}, false
+}
+
+// AvailableProviders returns the providers whose credentials resolve
+func AvailableProviders(providers []Provider) []Provider {
+	var available []Provider
+	for _, p := range providers {
+		if _, err := p.Token(); err == nil {
+			available = append(available, p)
+		}
+	}
+	return available
+}
+
+// Token resolves the provider's API token. Providers without AuthTokenEnv
+// or AuthTokenSecret need no token and resolve to "".
+func (p Provider) Token() (string, error) {
+	if p.AuthTokenSecret != "" {
+		token, err := ResolveSecret(p.AuthTokenSecret)
+		if err != nil {
+			return "", fmt.Errorf("provider %s: %w", p.Name, err)
+		}
+		return token, nil
+	}
+	if p.AuthTokenEnv == "" {
+		return "", nil
+	}
+	token := os.Getenv(p.AuthTokenEnv)
+	if token == "" {
+		return "", fmt.Errorf("provider %s requires the %s environment variable to be set", p.Name, p.AuthTokenEnv)
+	}
+	return token, nil
+}
+
+// TokenRef returns where the provider's API token comes from, "secret:NAME"
+// or "env:NAME", or "" when it needs none. See ResolveSecretRef.
+func (p Provider) TokenRef() string {
+	if p.AuthTokenSecret != "" {
+		return "secret:" + p.AuthTokenSecret
+	}
+	if p.AuthTokenEnv != "" {
+		return "env:" + p.AuthTokenEnv
+	}
+	return ""
+}
+
+// credentialChange names the first setting of p that decides where the
+// token goes, which token is sent or what environment Claude Code gets and
+// that differs from old, or returns "" when there is none
+func credentialChange(old, p Provider) string {
+	switch {
+	case p.endpoint() != old.endpoint():
+		return "endpoint"
+	case p.AuthTokenEnv != old.AuthTokenEnv:
+		return "authTokenEnv"
+	case p.AuthTokenSecret != old.AuthTokenSecret:
+		return "authTokenSecret"
+	case !maps.Equal(p.Env, old.Env):
+		return "env"
+	case !maps.Equal(p.ModelEnv, old.ModelEnv):
+		return "modelEnv"
+	}
+	return ""
+}
+
+// endpoint returns the base URL Claude Code is pointed at, which is where
+// the provider's token is sent, following the precedence of LaunchEnv
+func (p Provider) endpoint() string {
+	if p.BaseURL != "" {
+		return p.BaseURL
+	}
+	if url := p.ModelEnv["ANTHROPIC_BASE_URL"]; url != "" {
+		return url
+	}
+	return p.Env["ANTHROPIC_BASE_URL"]
+}
+
+// LaunchEnv returns the environment variables Claude Code needs to talk to
+// the provider, including its model mappings
+func (p Provider) LaunchEnv() (map[string]string, error) {
+	token, err := p.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string, len(p.Env)+len(p.ModelEnv)+2)
+	for name, value := range p.Env {
+		env[name] = value
+	}
+	for name, value := range p.ModelEnv {
+		env[name] = value
+	}
+	if p.BaseURL != "" {
+		env["ANTHROPIC_BASE_URL"] = p.BaseURL
+	}
+	if token != "" {
+		env["ANTHROPIC_AUTH_TOKEN"] = token
+	}
+	return env, nil
+}
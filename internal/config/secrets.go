@@ -0,0 +1,86 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var secretResolver func(name string) (string, error)
+
+// secretRef matches ${secret:NAME} placeholders in MCP configuration files
+var secretRef = regexp.MustCompile(`\$\{secret:([A-Za-z0-9_.-]+)\}`)
+
+// SetSecretResolver installs the function used to look up secrets referenced
+// by provider profiles and MCP configuration files
+func SetSecretResolver(resolve func(name string) (string, error)) {
+	secretResolver = resolve
+}
+
+// ResolveSecret looks up a secret through the installed resolver
+func ResolveSecret(name string) (string, error) {
+	if secretResolver == nil {
+		return "", errors.New("secret store is not available")
+	}
+	return secretResolver(name)
+}
+
+// ResolveSecretRef looks up a reference returned by Provider.TokenRef
+func ResolveSecretRef(ref string) (string, error) {
+	kind, name, _ := strings.Cut(ref, ":")
+	switch kind {
+	case "secret":
+		return ResolveSecret(name)
+	case "env":
+		value := os.Getenv(name)
+		if value == "" {
+			return "", fmt.Errorf("the %s environment variable is not set", name)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("unknown secret reference %s", ref)
+}
+
+// ResolveMCPFile returns a path Claude Code can load for the given MCP
+// configuration file. Files referencing ${secret:NAME} are copied to a
+// private temporary file with the secrets filled in; generated reports
+// whether that happened, in which case the caller owns the new file.
+func ResolveMCPFile(path string) (resolved string, generated bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !secretRef.Match(data) {
+		return path, false, nil
+	}
+
+	var resolveErr error
+	out := secretRef.ReplaceAllFunc(data, func(ref []byte) []byte {
+		name := string(secretRef.FindSubmatch(ref)[1])
+		value, err := ResolveSecret(name)
+		if err != nil {
+			resolveErr = err
+			return ref
+		}
+		// Placeholders sit inside JSON strings, so escape the value as one
+		quoted, _ := json.Marshal(value)
+		return quoted[1 : len(quoted)-1]
+	})
+	if resolveErr != nil {
+		return "", false, fmt.Errorf("failed to resolve secrets in %s: %w", path, resolveErr)
+	}
+
+	f, err := os.CreateTemp("", "cc-launcher-mcp-*.json")
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create resolved copy of %s: %w", path, err)
+	}
+	defer f.Close()
+	if _, err := f.Write(out); err != nil {
+		os.Remove(f.Name())
+		return "", false, fmt.Errorf("failed to write resolved copy of %s: %w", path, err)
+	}
+	return f.Name(), true, nil
+}
@@ -0,0 +1,362 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Preset is a named launch configuration selectable with --preset.
+// MCP lists configuration names as accepted by FindMCPConfig and Args are
+// passed to Claude Code verbatim.
+type Preset struct {
+	MCP      []string `json:"mcp,omitempty"`
+	Args     []string `json:"args,omitempty"`
+	Yolo     bool     `json:"yolo,omitempty"`
+	Happy    bool     `json:"happy,omitempty"`
+	Resume   bool     `json:"resume,omitempty"`
+	Continue bool     `json:"continue,omitempty"`
+	Provider string   `json:"provider,omitempty"`
+	// Providers is a failover chain tried in order; the first provider
+	// passing the pre-flight check is used
+	Providers []string `json:"providers,omitempty"`
+	Model    string   `json:"model,omitempty"`
+	Account  string   `json:"account,omitempty"`
+	Target   string   `json:"target,omitempty"`
+	// PermissionMode is passed as --permission-mode, see PermissionModes
+	PermissionMode string `json:"permissionMode,omitempty"`
+	// AllowedTools and DisallowedTools are tool patterns such as
+	// "Bash(git log:*)" passed as --allowedTools and --disallowedTools
+	AllowedTools    []string `json:"allowedTools,omitempty"`
+	DisallowedTools []string `json:"disallowedTools,omitempty"`
+	// AddDirs are added to the configured additional directories
+	AddDirs []string `json:"addDirs,omitempty"`
+	// Prompts name the prompt fragments appended to the system prompt, as
+	// accepted by FindPrompt
+	Prompts []string `json:"prompts,omitempty"`
+	// Settings names a Claude Code settings variant, as accepted by
+	// FindSettingsVariant
+	Settings string `json:"settings,omitempty"`
+}
+
+// DefaultModels are offered by the TUI model picker when the active provider
+// does not supply its own list
+var DefaultModels = []string{"sonnet", "opus", "haiku"}
+
+// Settings holds the launcher's own configuration, read from
+// ~/.claude/launcher/config.json and .claude/launcher/config.json.
+type Settings struct {
+	// Args are passed to Claude Code on every launch. User args come
+	// before project args.
+	Args    []string          `json:"args,omitempty"`
+	Presets map[string]Preset `json:"presets,omitempty"`
+	// Models are added to DefaultModels in the model picker
+	Models []string `json:"models,omitempty"`
+	// Preflight controls the provider check run before launching
+	Preflight PreflightSettings `json:"preflight"`
+	// Accounts are Claude logins selectable in the TUI or with --account
+	Accounts map[string]Account `json:"accounts,omitempty"`
+	// Supervise keeps the launcher running while Claude Code runs so it can
+	// clean up and report when the session ends
+	Supervise bool `json:"supervise,omitempty"`
+	// Targets add to or replace the built-in launch targets
+	Targets map[string]Target `json:"targets,omitempty"`
+	// Yolo restricts launches that skip permission checks
+	Yolo YoloSettings `json:"yolo"`
+	// Checkpoints controls the git checkpoints recorded before launching
+	Checkpoints CheckpointSettings `json:"checkpoints"`
+	// Worktrees controls where --worktree creates git worktrees
+	Worktrees WorktreeSettings `json:"worktrees"`
+	// AddDirs are additional directories Claude Code may access, passed
+	// as --add-dir. User directories come before project directories.
+	AddDirs []string `json:"addDirs,omitempty"`
+	// ProjectProviderTokens are the tokens, "env:NAME" or "secret:NAME",
+	// that providers added by a project's providers.json may use. Only the
+	// user's config.json can set them.
+	ProjectProviderTokens []string `json:"projectProviderTokens,omitempty"`
+}
+
+// Checkpoint modes for CheckpointSettings.When
+const (
+	CheckpointYolo   = "yolo"
+	CheckpointAlways = "always"
+	CheckpointNever  = "never"
+)
+
+// CheckpointSettings controls when a git checkpoint of the working tree is
+// recorded before Claude Code starts
+type CheckpointSettings struct {
+	// When is CheckpointYolo (the default), CheckpointAlways or
+	// CheckpointNever. The project settings can only make it stricter.
+	When string `json:"when,omitempty"`
+}
+
+// Enabled reports whether a launch with or without yolo gets a checkpoint
+func (c CheckpointSettings) Enabled(yolo bool) bool {
+	switch c.When {
+	case CheckpointAlways:
+		return true
+	case CheckpointNever:
+		return false
+	}
+	return yolo
+}
+
+// checkpointRank orders checkpoint modes by how often they record one.
+// Unknown modes rank lowest so a project cannot use them to relax When.
+func checkpointRank(when string) int {
+	switch when {
+	case CheckpointNever:
+		return 0
+	case "", CheckpointYolo:
+		return 1
+	case CheckpointAlways:
+		return 2
+	}
+	return -1
+}
+
+// YoloSettings restricts launches with --dangerously-skip-permissions
+type YoloSettings struct {
+	// RequireSandbox refuses yolo on targets that run on the host
+	RequireSandbox bool `json:"requireSandbox,omitempty"`
+	// RefuseDirs are directories in which yolo is refused, in addition to
+	// the home directory and /. Subdirectories are not affected. A leading
+	// ~ is expanded.
+	RefuseDirs []string `json:"refuseDirs,omitempty"`
+	// ProtectedBranches are warned about when checked out; nil means
+	// main and master. The project settings can only add branches.
+	ProtectedBranches []string `json:"protectedBranches,omitempty"`
+	// AllowDirty turns off the warning about uncommitted changes; only the
+	// user settings can set it
+	AllowDirty bool `json:"allowDirty,omitempty"`
+	// SensitiveMCP names MCP configurations, as accepted by --mcp, that
+	// need typed confirmation before they are used with yolo
+	SensitiveMCP []string `json:"sensitiveMCP,omitempty"`
+	// AlwaysConfirm shows the confirmation even when every guard passes
+	AlwaysConfirm bool `json:"alwaysConfirm,omitempty"`
+}
+
+// DefaultProtectedBranches are used when ProtectedBranches is not set
+var DefaultProtectedBranches = []string{"main", "master"}
+
+// Branches returns the protected branch names
+func (y YoloSettings) Branches() []string {
+	if y.ProtectedBranches == nil {
+		return DefaultProtectedBranches
+	}
+	return y.ProtectedBranches
+}
+
+// IsSensitive reports whether the MCP configuration at path is listed in
+// SensitiveMCP by path, by name or by origin-qualified name
+func (y YoloSettings) IsSensitive(path string) bool {
+	origin := OriginLocal
+	abs, _ := filepath.Abs(path)
+	if homeDir, err := os.UserHomeDir(); err == nil && isWithin(abs, filepath.Join(homeDir, ".claude", "mcp")) {
+		origin = OriginGlobal
+	}
+	name := strings.TrimSuffix(filepath.Base(path), ".json")
+	for _, entry := range y.SensitiveMCP {
+		if entry == path || entry == name || entry == origin+":"+name {
+			return true
+		}
+	}
+	return false
+}
+
+// PreflightSettings controls the provider connectivity and auth check
+type PreflightSettings struct {
+	Disabled bool `json:"disabled,omitempty"`
+	// Timeout is a Go duration such as "3s"
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// TimeoutDuration parses Timeout, returning 0 when it is not set
+func (p PreflightSettings) TimeoutDuration() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid preflight timeout %q: %w", p.Timeout, err)
+	}
+	return d, nil
+}
+
+// LauncherDir returns the launcher configuration directory for the given
+// scope: ".claude/launcher" for the project or "~/.claude/launcher" for the user.
+func LauncherDir(global bool) (string, error) {
+	if !global {
+		return filepath.Join(".claude", "launcher"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".claude", "launcher"), nil
+}
+
+// LoadSettings reads the user settings followed by the project settings.
+// Entries from the project file override user entries with the same name,
+// except that the project can only tighten the yolo guards and checkpoints,
+// so a cloned repository cannot turn them off, its args cannot skip
+// permission checks and it cannot allow provider tokens. Missing files are
+// not an error.
+func LoadSettings() (Settings, error) {
+	settings := Settings{Presets: map[string]Preset{}, Accounts: map[string]Account{}, Targets: map[string]Target{}}
+
+	layers, err := loadLayers[Settings]("config.json")
+	if err != nil {
+		return settings, err
+	}
+
+	for _, l := range layers {
+		layer := l.value
+		// Skipping permission checks on every launch is the user's call
+		if flag := SkipPermissionsFlag(layer.Args); flag != "" && !l.global {
+			return settings, fmt.Errorf("the project's config.json passes %s in args, which skips permission checks; only your own config.json can do that", flag)
+		}
+		settings.Args = append(settings.Args, layer.Args...)
+		settings.Models = append(settings.Models, layer.Models...)
+		settings.AddDirs = append(settings.AddDirs, layer.AddDirs...)
+		if layer.Preflight.Disabled {
+			settings.Preflight.Disabled = true
+		}
+		if layer.Supervise {
+			settings.Supervise = true
+		}
+		// The project can tighten the yolo restrictions but not relax them
+		if layer.Yolo.RequireSandbox {
+			settings.Yolo.RequireSandbox = true
+		}
+		if layer.Yolo.AlwaysConfirm {
+			settings.Yolo.AlwaysConfirm = true
+		}
+		settings.Yolo.RefuseDirs = append(settings.Yolo.RefuseDirs, layer.Yolo.RefuseDirs...)
+		settings.Yolo.SensitiveMCP = append(settings.Yolo.SensitiveMCP, layer.Yolo.SensitiveMCP...)
+		switch {
+		case layer.Yolo.ProtectedBranches == nil:
+		case l.global:
+			settings.Yolo.ProtectedBranches = layer.Yolo.ProtectedBranches
+		default:
+			branches := slices.Clone(settings.Yolo.Branches())
+			for _, branch := range layer.Yolo.ProtectedBranches {
+				if !slices.Contains(branches, branch) {
+					branches = append(branches, branch)
+				}
+			}
+			settings.Yolo.ProtectedBranches = branches
+		}
+		if layer.Yolo.AllowDirty && l.global {
+			settings.Yolo.AllowDirty = true
+		}
+		if l.global {
+			settings.ProjectProviderTokens = layer.ProjectProviderTokens
+		}
+		if when := layer.Checkpoints.When; when != "" && (l.global || checkpointRank(when) > checkpointRank(settings.Checkpoints.When)) {
+			settings.Checkpoints.When = when
+		}
+		if layer.Worktrees.Dir != "" {
+			settings.Worktrees.Dir = layer.Worktrees.Dir
+		}
+		if layer.Worktrees.Config != "" {
+			settings.Worktrees.Config = layer.Worktrees.Config
+		}
+		if layer.Preflight.Timeout != "" {
+			settings.Preflight.Timeout = layer.Preflight.Timeout
+		}
+		for name, preset := range layer.Presets {
+			settings.Presets[name] = preset
+		}
+		for name, account := range layer.Accounts {
+			settings.Accounts[name] = account
+		}
+		for name, target := range layer.Targets {
+			target.Project = !l.global
+			settings.Targets[name] = target
+		}
+	}
+	return settings, nil
+}
+
+// PresetNames returns the names of all presets in sorted order
+func (s Settings) PresetNames() []string {
+	names := make([]string, 0, len(s.Presets))
+	for name := range s.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ModelsFor returns the models offered for the given provider, which is the
+// zero Provider when talking to Anthropic directly. A provider with its own
+// model list replaces the defaults; otherwise DefaultModels are followed by
+// the configured Models.
+func (s Settings) ModelsFor(provider Provider) []string {
+	if len(provider.Models) > 0 {
+		return provider.Models
+	}
+
+	var models []string
+	for _, model := range append(append([]string{}, DefaultModels...), s.Models...) {
+		if !slices.Contains(models, model) {
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// layer is the content of a configuration file and whether it is the
+// user's file rather than the project's
+type layer[T any] struct {
+	value  T
+	global bool
+}
+
+// loadLayers decodes the named file from the user and then the project
+// launcher directory, returning one layer per file that exists. In the
+// home directory both are the same file, which is only the user layer.
+func loadLayers[T any](name string) ([]layer[T], error) {
+	var layers []layer[T]
+	var userFile os.FileInfo
+	for _, global := range []bool{true, false} {
+		dir, err := LauncherDir(global)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if global {
+			userFile = info
+		} else if userFile != nil && os.SameFile(info, userFile) {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+		}
+		if debugMode {
+			log.Printf("Info: Loaded launcher settings from %s", path)
+		}
+		layers = append(layers, layer[T]{value: value, global: global})
+	}
+	return layers, nil
+}
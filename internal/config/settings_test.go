@@ -0,0 +1,73 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupLayers makes a fresh home and project directory, changes into the
+// project and writes name to the user and project launcher directories.
+// An empty content leaves that file out.
+func setupLayers(t *testing.T, name, user, project string) {
+	t.Helper()
+	home, dir := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Chdir(dir)
+	for _, f := range []struct{ dir, content string }{
+		{filepath.Join(home, ".claude", "launcher"), user},
+		{filepath.Join(dir, ".claude", "launcher"), project},
+	} {
+		if f.content == "" {
+			continue
+		}
+		if err := os.MkdirAll(f.dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(f.dir, name), []byte(f.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadSettingsRejectsProjectSkipPermissionsArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		project string
+		wantErr bool
+	}{
+		{name: "user args", user: `{"args":["--dangerously-skip-permissions"]}`},
+		{name: "project args", project: `{"args":["--verbose"]}`},
+		{name: "project skips permissions", project: `{"args":["--dangerously-skip-permissions"]}`, wantErr: true},
+		{name: "project bypass mode", project: `{"args":["--permission-mode","bypassPermissions"]}`, wantErr: true},
+		{name: "project bypass mode with =", project: `{"args":["--permission-mode=bypassPermissions"]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupLayers(t, "config.json", tt.user, tt.project)
+			_, err := LoadSettings()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSettings() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), "skips permission checks") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadSettingsInHomeDirectory(t *testing.T) {
+	setupLayers(t, "config.json", `{"args":["--verbose"]}`, "")
+	home, _ := os.UserHomeDir()
+	t.Chdir(home)
+
+	settings, err := LoadSettings()
+	if err != nil {
+		t.Fatalf("LoadSettings: %v", err)
+	}
+	if len(settings.Args) != 1 {
+		t.Errorf("Args = %q, want the user args once", settings.Args)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SettingsVariant is an alternative Claude Code settings file, such as
+// .claude/settings.review.json, passed as --settings
+type SettingsVariant struct {
+	// Path is absolute so the file is found from a worktree as well
+	Path   string
+	Name   string
+	Origin string
+	Error  string
+}
+
+func (v SettingsVariant) file() (string, string, string) {
+	return v.Name, v.Origin, v.Path
+}
+
+// Label returns the display label used by the TUI, e.g. "review (local)"
+func (v SettingsVariant) Label() string {
+	return fmt.Sprintf("%s (%s)", v.Name, v.Origin)
+}
+
+// DiscoverSettingsVariants finds .claude/settings.<name>.json files and,
+// unless localOnly is set, ~/.claude/settings.<name>.json files and checks
+// that they hold a JSON object. settings.local.json is left out because
+// Claude Code always reads it. Local variants are listed first.
+func DiscoverSettingsVariants(localOnly bool) ([]SettingsVariant, error) {
+	return discover("", "settings.*.json", localOnly, func(path, origin string) (SettingsVariant, bool, error) {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "settings."), ".json")
+		if name == "local" {
+			return SettingsVariant{}, false, nil
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return SettingsVariant{}, false, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		return SettingsVariant{Path: abs, Name: name, Origin: origin, Error: checkSettingsFile(abs)}, true, nil
+	})
+}
+
+// FindSettingsVariant looks up a discovered variant by name or path. Like
+// FindMCPConfig, a name may be qualified with its origin ("global:review").
+func FindSettingsVariant(variants []SettingsVariant, name string) (SettingsVariant, bool) {
+	return findDiscovered(variants, name)
+}
+
+// checkSettingsFile returns why path is not a usable settings file, or ""
+func checkSettingsFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err.Error()
+	}
+	var settings any
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return "invalid JSON: " + err.Error()
+	}
+	if _, ok := settings.(map[string]any); !ok {
+		return "not a JSON object"
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ProjectState holds the choices remembered for a single project directory
+type ProjectState struct {
+	Model string `json:"model,omitempty"`
+}
+
+// State holds choices remembered between launches. It is stored in
+// ~/.claude/launcher/state.json and keyed by absolute project directory.
+type State struct {
+	Projects map[string]ProjectState `json:"projects,omitempty"`
+}
+
+// LoadState reads the launcher state file. A missing file yields an empty state.
+func LoadState() (State, error) {
+	state := State{Projects: map[string]ProjectState{}}
+
+	path, err := statePath()
+	if err != nil {
+		return state, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return state, nil
+		}
+		return state, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if state.Projects == nil {
+		state.Projects = map[string]ProjectState{}
+	}
+	return state, nil
+}
+
+// Save writes the state file, creating the launcher directory if needed
+func (s State) Save() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Project returns the remembered state for the current working directory
+func (s State) Project() ProjectState {
+	dir, err := projectKey()
+	if err != nil {
+		return ProjectState{}
+	}
+	return s.Projects[dir]
+}
+
+// SetProject replaces the remembered state for the current working directory
+func (s *State) SetProject(p ProjectState) error {
+	dir, err := projectKey()
+	if err != nil {
+		return err
+	}
+	if s.Projects == nil {
+		s.Projects = map[string]ProjectState{}
+	}
+	s.Projects[dir] = p
+	return nil
+}
+
+func statePath() (string, error) {
+	dir, err := LauncherDir(true)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+func projectKey() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	return filepath.Abs(dir)
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// DefaultTarget is the launch target used when none is chosen
+const DefaultTarget = "claude"
+
+// Target is a named way of starting Claude Code, such as the claude binary
+// itself or a wrapper like happy. Targets are read from the "targets" object
+// in config.json, keyed by name.
+type Target struct {
+	Name string `json:"-"`
+	// Executable is looked up in PATH unless it is a path
+	Executable string `json:"executable"`
+	// Args are placed before the arguments the launcher builds
+	Args []string `json:"args,omitempty"`
+	// Env holds additional environment variables
+	Env map[string]string `json:"env,omitempty"`
+	// Fallback names the target to try when Executable is not found
+	Fallback string `json:"fallback,omitempty"`
+	// Container runs Executable inside a container instead of on the host
+	Container *Container `json:"container,omitempty"`
+	// Sandbox runs Executable on the host inside Linux namespaces
+	Sandbox *Sandbox `json:"sandbox,omitempty"`
+	// Tmux opens several launcher sessions in tmux instead of running
+	// Executable, which is not needed then
+	Tmux *Tmux `json:"tmux,omitempty"`
+	// Project is set for targets defined in the project settings
+	Project bool `json:"-"`
+}
+
+// Container configures a target that runs Claude Code in a Docker or Podman
+// container. The project directory is bind-mounted at the same path;
+// selected MCP configurations and the Claude login are mounted read-only.
+type Container struct {
+	Image string `json:"image"`
+	// Runtime is "docker" or "podman". When empty docker is used if it is
+	// installed, otherwise podman.
+	Runtime string `json:"runtime,omitempty"`
+	// Network is passed as --network, e.g. "none" to cut Claude Code off
+	// from everything but the mounted files. Empty keeps the default.
+	Network string `json:"network,omitempty"`
+	// Mounts are additional volume specs (source:target[:options]). A
+	// leading ~ in the source is expanded.
+	Mounts []string `json:"mounts,omitempty"`
+	// Args are extra arguments for "<runtime> run", placed before the image
+	Args []string `json:"args,omitempty"`
+}
+
+// Sandbox configures a target that runs Claude Code in Linux namespaces
+// through bubblewrap or unshare, a lighter alternative to a container.
+// Everything but the project directory, the scratch directory and Claude
+// Code's own configuration is read-only.
+type Sandbox struct {
+	// Tool is "bwrap" or "unshare". When empty bwrap is used if it is
+	// installed, otherwise unshare.
+	Tool string `json:"tool,omitempty"`
+	// NoNetwork gives Claude Code an empty network namespace. This cuts off
+	// every endpoint, including the API.
+	NoNetwork bool `json:"noNetwork,omitempty"`
+	// Scratch is a writable directory exported as TMPDIR. It defaults to a
+	// per-user directory below the system temp directory. A leading ~ is
+	// expanded.
+	Scratch string `json:"scratch,omitempty"`
+	// Writable are additional paths kept writable. A leading ~ is expanded.
+	Writable []string `json:"writable,omitempty"`
+}
+
+// Sandboxed reports whether the target isolates Claude Code from the host.
+// Targets from the project settings never count, since the project could
+// make any path writable or mount it.
+func (t Target) Sandboxed() bool {
+	return !t.Project && (t.Container != nil || t.Sandbox != nil)
+}
+
+// builtinTargets are available without any configuration. A target with the
+// same name in config.json replaces the built-in one.
+var builtinTargets = map[string]Target{
+	"claude": {Executable: "claude"},
+	"happy":  {Executable: "happy", Fallback: "claude"},
+	"npx":    {Executable: "npx", Args: []string{"@anthropic-ai/claude-code"}},
+}
+
+func init() {
+	// Namespaces are Linux only
+	if runtime.GOOS == "linux" {
+		builtinTargets["sandbox"] = Target{Executable: "claude", Sandbox: &Sandbox{}}
+	}
+}
+
+// Command returns the target's executable and argument prefix as a single
+// string for display
+func (t Target) Command() string {
+	if t.Tmux != nil {
+		return fmt.Sprintf("tmux with %d panes", len(t.Tmux.Panes))
+	}
+	command := strings.Join(append([]string{t.Executable}, t.Args...), " ")
+	if t.Container != nil {
+		command += " in " + t.Container.Image
+	}
+	if t.Sandbox != nil {
+		command += " in sandbox"
+	}
+	return command
+}
+
+// LaunchTargets returns the built-in targets merged with the configured
+// ones, sorted by name
+func (s Settings) LaunchTargets() []Target {
+	merged := make(map[string]Target, len(builtinTargets)+len(s.Targets))
+	for name, t := range builtinTargets {
+		merged[name] = t
+	}
+	for name, t := range s.Targets {
+		merged[name] = t
+	}
+
+	targets := make([]Target, 0, len(merged))
+	for name, t := range merged {
+		t.Name = name
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Name < targets[j].Name
+	})
+	return targets
+}
+
+// FindTarget returns the launch target with the given name
+func (s Settings) FindTarget(name string) (Target, bool) {
+	for _, t := range s.LaunchTargets() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+// TargetChain returns the named target followed by its fallbacks in the
+// order they should be tried. An empty name means DefaultTarget.
+func (s Settings) TargetChain(name string) ([]Target, error) {
+	if name == "" {
+		name = DefaultTarget
+	}
+
+	var chain []Target
+	seen := make(map[string]bool)
+	for name != "" {
+		if seen[name] {
+			return nil, fmt.Errorf("launch target %s falls back to itself", name)
+		}
+		seen[name] = true
+
+		t, ok := s.FindTarget(name)
+		if !ok {
+			if len(chain) == 0 {
+				return nil, fmt.Errorf("unknown launch target %s", name)
+			}
+			return nil, fmt.Errorf("launch target %s falls back to unknown target %s", chain[len(chain)-1].Name, name)
+		}
+		if t.Tmux != nil {
+			if len(t.Tmux.Panes) == 0 {
+				return nil, fmt.Errorf("launch target %s has no tmux panes", name)
+			}
+			// The launch options depend on whether the panes or Claude
+			// Code itself get launched, so that must be known up front
+			if len(chain) > 0 {
+				return nil, fmt.Errorf("launch target %s falls back to tmux target %s", chain[len(chain)-1].Name, name)
+			}
+			if t.Fallback != "" {
+				return nil, fmt.Errorf("tmux launch target %s cannot have a fallback", name)
+			}
+		} else if t.Executable == "" {
+			return nil, fmt.Errorf("launch target %s has no executable", name)
+		}
+		if t.Container != nil && t.Container.Image == "" {
+			return nil, fmt.Errorf("launch target %s has no container image", name)
+		}
+		if t.Container != nil && t.Sandbox != nil {
+			return nil, fmt.Errorf("launch target %s cannot run both in a container and in a sandbox", name)
+		}
+		if t.Sandbox != nil && runtime.GOOS != "linux" {
+			return nil, fmt.Errorf("launch target %s needs Linux namespaces", name)
+		}
+		chain = append(chain, t)
+		name = t.Fallback
+	}
+	return chain, nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Tmux configures a target that starts several launcher sessions side by
+// side in tmux, each in its own directory or worktree with its own preset
+type Tmux struct {
+	// Session is the tmux session name, DefaultTmuxSession when empty
+	Session string `json:"session,omitempty"`
+	// Layout is a tmux layout such as "tiled" (the default) or
+	// "even-horizontal"
+	Layout string `json:"layout,omitempty"`
+	// Windows gives every pane a window of its own instead of splitting one
+	Windows bool       `json:"windows,omitempty"`
+	Panes   []TmuxPane `json:"panes"`
+}
+
+// TmuxPane is one launcher session of a tmux target
+type TmuxPane struct {
+	// Name labels the pane; it defaults to the worktree, preset or directory
+	Name string `json:"name,omitempty"`
+	// Dir is the working directory, relative to the current one. A leading
+	// ~ is expanded.
+	Dir string `json:"dir,omitempty"`
+	// Worktree and Preset are passed as --worktree and --preset
+	Worktree string `json:"worktree,omitempty"`
+	Preset   string `json:"preset,omitempty"`
+	// Args are further launcher arguments, e.g. ["--mcp", "github"]
+	Args []string `json:"args,omitempty"`
+}
+
+// Label returns the pane's name or a fallback describing it
+func (p TmuxPane) Label() string {
+	switch {
+	case p.Name != "":
+		return p.Name
+	case p.Worktree != "":
+		return p.Worktree
+	case p.Preset != "":
+		return p.Preset
+	case p.Dir != "":
+		return filepath.Base(p.Dir)
+	}
+	return "claude"
+}
+
+// DefaultTmuxSession returns the session name used for the project in dir
+// when a tmux target does not name one: "cc-" and the directory name
+func DefaultTmuxSession(dir string) string {
+	// tmux does not allow . and : in session names
+	return "cc-" + strings.NewReplacer(".", "-", ":", "-").Replace(filepath.Base(dir))
+}
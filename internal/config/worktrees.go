@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultWorktreeDir is used when WorktreeSettings.Dir is not set
+const DefaultWorktreeDir = "../{repo}-worktrees"
+
+// Ways of bringing the project's .claude directory into a new worktree
+const (
+	WorktreeConfigLink = "link"
+	WorktreeConfigCopy = "copy"
+	WorktreeConfigNone = "none"
+)
+
+// WorktreeSettings controls the git worktrees created for --worktree
+type WorktreeSettings struct {
+	// Dir holds one worktree per branch. {repo} is replaced by the
+	// repository's directory name, a leading ~ is expanded and a relative
+	// path is taken from the repository root.
+	Dir string `json:"dir,omitempty"`
+	// Config is WorktreeConfigLink (the default), WorktreeConfigCopy or
+	// WorktreeConfigNone. Entries of .claude/ that the worktree does not
+	// have yet are symlinked or copied.
+	Config string `json:"config,omitempty"`
+}
+
+// Path returns the worktree location for branch in the repository at root.
+// Slashes in the branch name become dashes.
+func (w WorktreeSettings) Path(root, branch string) string {
+	dir := w.Dir
+	if dir == "" {
+		dir = DefaultWorktreeDir
+	}
+	dir = ExpandHome(strings.ReplaceAll(dir, "{repo}", filepath.Base(root)))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return filepath.Join(dir, strings.ReplaceAll(branch, "/", "-"))
+}
+
+// ConfigMode returns Config, checked and defaulted
+func (w WorktreeSettings) ConfigMode() (string, error) {
+	switch w.Config {
+	case "":
+		return WorktreeConfigLink, nil
+	case WorktreeConfigLink, WorktreeConfigCopy, WorktreeConfigNone:
+		return w.Config, nil
+	}
+	return "", fmt.Errorf("invalid worktrees config %q (use link, copy or none)", w.Config)
+}
@@ -0,0 +1,360 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Root returns the top-level directory of the work tree containing dir
+func Root(dir string) (string, error) {
+	return run(dir, "rev-parse", "--show-toplevel")
+}
+
+// Branch returns the checked-out branch in dir, or "" for a detached HEAD
+func Branch(dir string) (string, error) {
+	branch, err := run(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		// symbolic-ref fails on a detached HEAD, which has no branch
+		if _, headErr := run(dir, "rev-parse", "--verify", "HEAD"); headErr == nil {
+			return "", nil
+		}
+		return "", err
+	}
+	return branch, nil
+}
+
+// Dirty reports whether the work tree in dir has uncommitted changes,
+// including untracked files that are not ignored
+func Dirty(dir string) (bool, error) {
+	status, err := run(dir, "status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return status != "", nil
+}
+
+// run runs git in dir and returns its trimmed output. The error carries
+// git's own message.
+func run(dir string, args ...string) (string, error) {
+	return runEnv(dir, nil, args...)
+}
+
+// runEnv is run with the environment env, or the inherited one when nil
+func runEnv(dir string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Branches returns the local branch names of the repository containing dir
+func Branches(dir string) ([]string, error) {
+	out, err := run(dir, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// Worktree returns the work tree of the repository containing dir that has
+// branch checked out, adding one at path if there is none. A missing branch
+// is created from HEAD. created reports whether a work tree was added.
+func Worktree(dir, branch, path string) (worktree string, created bool, err error) {
+	out, err := run(dir, "worktree", "list", "--porcelain")
+	if err != nil {
+		return "", false, err
+	}
+	current := ""
+	for _, line := range strings.Split(out, "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			current = p
+		}
+		if line == "branch refs/heads/"+branch {
+			return current, false, nil
+		}
+	}
+
+	args := []string{"worktree", "add", path, branch}
+	if _, err := run(dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch); err != nil {
+		args = []string{"worktree", "add", "-b", branch, path}
+	}
+	if _, err := run(dir, args...); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
+
+// CheckpointPrefix is the ref namespace holding launcher checkpoints. It
+// is shared by all worktrees, with one directory per worktree below it
+// named like git's own: main-worktree or worktrees/<id>.
+const CheckpointPrefix = "refs/cc-launcher/checkpoints/"
+
+// Checkpoint is a snapshot of a work tree stored as a commit under
+// CheckpointPrefix
+type Checkpoint struct {
+	// Name is the last component of the ref name
+	Name string
+	// Worktree identifies the worktree the checkpoint was recorded in, see
+	// WorktreeID
+	Worktree string
+	Commit   string
+	Created  time.Time
+	Subject  string
+}
+
+// Ref returns the full ref name of the checkpoint
+func (c Checkpoint) Ref() string {
+	return CheckpointPrefix + c.Worktree + "/" + c.Name
+}
+
+// WorktreeID returns how git refers to the worktree containing dir:
+// main-worktree for the main one, worktrees/<id> for a linked one
+func WorktreeID(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--path-format=absolute", "--git-dir", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	gitDir, commonDir, _ := strings.Cut(out, "\n")
+	if gitDir == commonDir {
+		return "main-worktree", nil
+	}
+	return "worktrees/" + filepath.Base(gitDir), nil
+}
+
+// CreateCheckpoint records the work tree containing dir, including
+// untracked files that are not ignored, as a new checkpoint. The current
+// branch and the index are left alone.
+func CreateCheckpoint(dir, subject string) (Checkpoint, error) {
+	root, err := Root(dir)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	worktree, err := WorktreeID(root)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	tree, err := snapshot(root)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	args := []string{"commit-tree", tree, "-m", subject}
+	if head, err := run(root, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		args = append(args, "-p", head)
+	}
+	// Checkpoints are authored by the launcher, which also keeps them
+	// working when no git identity is configured
+	env := append(os.Environ(),
+		"GIT_AUTHOR_NAME=cc-launcher", "GIT_AUTHOR_EMAIL=cc-launcher@localhost",
+		"GIT_COMMITTER_NAME=cc-launcher", "GIT_COMMITTER_EMAIL=cc-launcher@localhost")
+	commit, err := runEnv(root, env, args...)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	created := time.Now()
+	cp := Checkpoint{Name: created.Format("20060102-150405"), Worktree: worktree, Commit: commit, Created: created, Subject: subject}
+	for i := 2; ; i++ {
+		if _, err := run(root, "rev-parse", "--verify", "--quiet", cp.Ref()); err != nil {
+			break
+		}
+		cp.Name = fmt.Sprintf("%s-%d", created.Format("20060102-150405"), i)
+	}
+	if _, err := run(root, "update-ref", cp.Ref(), commit, ""); err != nil {
+		return Checkpoint{}, err
+	}
+	return cp, nil
+}
+
+// Checkpoints returns the checkpoints of all worktrees of the repository
+// containing dir, newest first
+func Checkpoints(dir string) ([]Checkpoint, error) {
+	// Names sort by time as well and break ties within the same second
+	out, err := run(dir, "for-each-ref", "--sort=-refname", "--sort=-creatordate",
+		"--format=%(refname)%00%(objectname)%00%(creatordate:unix)%00%(subject)", CheckpointPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoints []Checkpoint
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		ref := strings.TrimPrefix(fields[0], CheckpointPrefix)
+		slash := strings.LastIndex(ref, "/")
+		if slash < 0 {
+			continue
+		}
+		unix, _ := strconv.ParseInt(fields[2], 10, 64)
+		checkpoints = append(checkpoints, Checkpoint{
+			Name:     ref[slash+1:],
+			Worktree: ref[:slash],
+			Commit:   fields[1],
+			Created:  time.Unix(unix, 0),
+			Subject:  fields[3],
+		})
+	}
+	return checkpoints, nil
+}
+
+// FindCheckpoint returns the checkpoint with the given name, or the newest
+// one of the worktree containing dir when name is empty. A name matching
+// checkpoints of several worktrees means the one of this worktree; the
+// others can be named as <worktree>/<name>.
+func FindCheckpoint(dir, name string) (Checkpoint, error) {
+	worktree, err := WorktreeID(dir)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+	checkpoints, err := Checkpoints(dir)
+	if err != nil {
+		return Checkpoint{}, err
+	}
+
+	var found []Checkpoint
+	for _, cp := range checkpoints {
+		switch {
+		case name == "" && cp.Worktree == worktree, name == cp.Worktree+"/"+cp.Name:
+			return cp, nil
+		case name != "" && cp.Name == name:
+			if cp.Worktree == worktree {
+				return cp, nil
+			}
+			found = append(found, cp)
+		}
+	}
+	switch {
+	case name == "":
+		return Checkpoint{}, fmt.Errorf("no checkpoints found for this worktree")
+	case len(found) == 1:
+		return found[0], nil
+	case len(found) > 1:
+		return Checkpoint{}, fmt.Errorf("checkpoint %s exists in several worktrees; name it as <worktree>/%s", name, name)
+	}
+	return Checkpoint{}, fmt.Errorf("unknown checkpoint %s", name)
+}
+
+// DiffCheckpoint shows the changes from the checkpoint to the current work
+// tree, including untracked files, with git's pager and colors
+func DiffCheckpoint(dir string, cp Checkpoint, args ...string) error {
+	root, err := Root(dir)
+	if err != nil {
+		return err
+	}
+	tree, err := snapshot(root)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("git", append([]string{"-C", root, "diff", cp.Commit, tree}, args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// RestoreCheckpoint makes the work tree match the checkpoint: files are
+// written back and files created since are removed, except ignored ones.
+// The branch and the index are left alone, so the restored changes show up
+// as unstaged.
+func RestoreCheckpoint(dir string, cp Checkpoint) error {
+	root, err := Root(dir)
+	if err != nil {
+		return err
+	}
+
+	saved, err := run(root, "ls-tree", "-r", "-z", "--name-only", cp.Commit)
+	if err != nil {
+		return err
+	}
+	keep := make(map[string]bool)
+	for _, path := range strings.Split(saved, "\x00") {
+		keep[path] = true
+	}
+	current, err := run(root, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return err
+	}
+	for _, path := range strings.Split(current, "\x00") {
+		if path == "" || keep[path] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(root, path)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+
+	return withTempIndex(root, "", func(env []string) error {
+		if _, err := runEnv(root, env, "read-tree", cp.Commit); err != nil {
+			return err
+		}
+		_, err := runEnv(root, env, "checkout-index", "--all", "--force")
+		return err
+	})
+}
+
+// snapshot writes the work tree at root, including untracked files that
+// are not ignored, as a tree object and returns its id. A copy of the index
+// is used so the real one is not touched.
+func snapshot(root string) (string, error) {
+	index, err := run(root, "rev-parse", "--path-format=absolute", "--git-path", "index")
+	if err != nil {
+		return "", err
+	}
+
+	var tree string
+	err = withTempIndex(root, index, func(env []string) error {
+		if _, err := runEnv(root, env, "add", "--all"); err != nil {
+			return err
+		}
+		tree, err = runEnv(root, env, "write-tree")
+		return err
+	})
+	return tree, err
+}
+
+// withTempIndex calls fn with an environment pointing GIT_INDEX_FILE at a
+// temporary index, initialised from the index file at from if it exists
+func withTempIndex(root, from string, fn func(env []string) error) error {
+	tmp, err := os.CreateTemp("", "cc-launcher-index-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	data, err := os.ReadFile(from)
+	if from == "" || os.IsNotExist(err) {
+		data, err = nil, nil
+	}
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prepare temporary index: %w", err)
+	}
+	// git rejects an empty file as an index, so start without one
+	if len(data) == 0 {
+		os.Remove(tmp.Name())
+	}
+	return fn(append(os.Environ(), "GIT_INDEX_FILE="+tmp.Name()))
+}
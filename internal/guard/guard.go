@@ -0,0 +1,132 @@
+package guard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/git"
+)
+
+// Level is the outcome of a guard, ordered by severity
+type Level int
+
+const (
+	// Pass means the guard found nothing to worry about
+	Pass Level = iota
+	// Warn is shown before launching but does not stop it
+	Warn
+	// Confirm requires typing ConfirmWord before launching
+	Confirm
+	// Refuse stops the launch
+	Refuse
+)
+
+// ConfirmWord must be typed to launch when a guard asks for confirmation
+const ConfirmWord = "yolo"
+
+// Result is the outcome of a single guard
+type Result struct {
+	Level   Level
+	Message string
+}
+
+// Check runs the yolo guards for a launch in dir with the given MCP
+// configuration files
+func Check(s config.YoloSettings, dir string, mcpFiles []string) []Result {
+	results := []Result{checkDir(s, dir)}
+	results = append(results, checkRepo(s, dir)...)
+
+	var sensitive []string
+	for _, file := range mcpFiles {
+		if s.IsSensitive(file) {
+			sensitive = append(sensitive, strings.TrimSuffix(filepath.Base(file), ".json"))
+		}
+	}
+	if len(sensitive) > 0 {
+		results = append(results, Result{Confirm, "Sensitive MCP configuration selected: " + strings.Join(sensitive, ", ")})
+	} else if len(mcpFiles) > 0 {
+		results = append(results, Result{Pass, "No sensitive MCP configuration selected"})
+	}
+	return results
+}
+
+// Worst returns the most severe level in results
+func Worst(results []Result) Level {
+	worst := Pass
+	for _, r := range results {
+		worst = max(worst, r.Level)
+	}
+	return worst
+}
+
+// Refusal returns the message of the first refusing guard, or "" when the
+// launch may go ahead
+func Refusal(results []Result) string {
+	for _, r := range results {
+		if r.Level == Refuse {
+			return r.Message
+		}
+	}
+	return ""
+}
+
+// checkDir refuses directories that give Claude Code far more than a project
+func checkDir(s config.YoloSettings, dir string) Result {
+	refused := []string{"/"}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		refused = append(refused, homeDir)
+	}
+	for _, d := range s.RefuseDirs {
+		refused = append(refused, config.ExpandHome(d))
+	}
+
+	resolved := resolve(dir)
+	for _, d := range refused {
+		if resolve(d) == resolved {
+			return Result{Refuse, fmt.Sprintf("Refusing to skip permission checks in %s", dir)}
+		}
+	}
+	return Result{Pass, "Working directory " + dir}
+}
+
+// checkRepo warns about changes that could not be told apart from Claude
+// Code's and about working directly on a protected branch. Outside a git
+// repository there is nothing to check.
+func checkRepo(s config.YoloSettings, dir string) []Result {
+	branch, err := git.Branch(dir)
+	if err != nil {
+		return nil
+	}
+
+	var results []Result
+	if slices.Contains(s.Branches(), branch) {
+		results = append(results, Result{Warn, "On protected branch " + branch})
+	} else if branch != "" {
+		results = append(results, Result{Pass, "On branch " + branch})
+	}
+
+	if !s.AllowDirty {
+		dirty, err := git.Dirty(dir)
+		switch {
+		case err != nil:
+			results = append(results, Result{Warn, err.Error()})
+		case dirty:
+			results = append(results, Result{Warn, "Working tree has uncommitted changes"})
+		default:
+			results = append(results, Result{Pass, "Working tree is clean"})
+		}
+	}
+	return results
+}
+
+// resolve cleans path and follows symlinks where possible
+func resolve(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
@@ -0,0 +1,109 @@
+package guard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"cc-launcher/internal/config"
+)
+
+// gitRepo creates a repository with one commit on branch
+func gitRepo(t *testing.T, branch string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", "--initial-branch", branch},
+		{"-c", "user.name=test", "-c", "user.email=test@localhost", "commit", "--quiet", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", args[0], err, out)
+		}
+	}
+	return dir
+}
+
+func TestCheckDir(t *testing.T) {
+	home, project := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	refused := filepath.Join(project, "refused")
+	tests := []struct {
+		name string
+		dir  string
+		want Level
+	}{
+		{name: "root", dir: "/", want: Refuse},
+		{name: "home", dir: home, want: Refuse},
+		{name: "home with trailing slash", dir: home + "/", want: Refuse},
+		{name: "configured", dir: refused, want: Refuse},
+		{name: "project", dir: project, want: Pass},
+	}
+	s := config.YoloSettings{RefuseDirs: []string{refused}}
+	for _, tt := range tests {
+		if got := checkDir(s, tt.dir).Level; got != tt.want {
+			t.Errorf("%s: level = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRepo(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		dirty    bool
+		settings config.YoloSettings
+		want     Level
+	}{
+		{name: "clean feature branch", branch: "feature", want: Pass},
+		{name: "protected branch", branch: "main", want: Warn},
+		{name: "configured protected branch", branch: "release", settings: config.YoloSettings{ProtectedBranches: []string{"release"}}, want: Warn},
+		{name: "dirty", branch: "feature", dirty: true, want: Warn},
+		{name: "dirty allowed", branch: "feature", dirty: true, settings: config.YoloSettings{AllowDirty: true}, want: Pass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := gitRepo(t, tt.branch)
+			if tt.dirty {
+				if err := os.WriteFile(filepath.Join(dir, "new.txt"), []byte("x"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := Worst(checkRepo(tt.settings, dir)); got != tt.want {
+				t.Errorf("level = %v, want %v (%+v)", got, tt.want, checkRepo(tt.settings, dir))
+			}
+		})
+	}
+}
+
+func TestCheckSensitiveMCP(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	s := config.YoloSettings{SensitiveMCP: []string{"prod-db"}}
+
+	results := Check(s, dir, []string{filepath.Join(dir, ".claude", "mcp", "prod-db.json")})
+	if Worst(results) != Confirm {
+		t.Errorf("Worst = %v, want Confirm for a sensitive MCP configuration", Worst(results))
+	}
+	if Refusal(results) != "" {
+		t.Errorf("Refusal = %q, want none", Refusal(results))
+	}
+
+	results = Check(s, dir, []string{filepath.Join(dir, ".claude", "mcp", "context7.json")})
+	if Worst(results) != Pass {
+		t.Errorf("Worst = %v, want Pass (%+v)", Worst(results), results)
+	}
+}
+
+func TestRefusal(t *testing.T) {
+	results := []Result{{Pass, "ok"}, {Warn, "careful"}, {Refuse, "no"}, {Refuse, "also no"}}
+	if got := Refusal(results); got != "no" {
+		t.Errorf("Refusal = %q, want the first refusing message", got)
+	}
+	if got := Worst(results); got != Refuse {
+		t.Errorf("Worst = %v, want Refuse", got)
+	}
+}
@@ -0,0 +1,118 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/launcher"
+)
+
+// Hook names, which are also the file names looked up in the hooks
+// directories
+const (
+	PreLaunch = "pre-launch"
+	PostExit  = "post-exit"
+)
+
+// Input is written to a hook's stdin as JSON. It describes the resolved
+// launch; credential values in Env are masked.
+type Input struct {
+	Hook       string   `json:"hook"`
+	Executable string   `json:"executable"`
+	Args       []string `json:"args"`
+	Dir        string   `json:"dir"`
+	// Env lists the variables added or changed for Claude Code
+	Env       []string `json:"env"`
+	Generated []string `json:"generated,omitempty"`
+	// ExitCode and DurationMs are set for post-exit hooks
+	ExitCode   *int  `json:"exitCode,omitempty"`
+	DurationMs int64 `json:"durationMs,omitempty"`
+}
+
+// NewInput describes plan for the named hook
+func NewInput(hook string, plan launcher.LaunchPlan) Input {
+	env := []string{}
+	for _, entry := range launcher.EnvDelta(plan) {
+		env = append(env, launcher.MaskEnv(entry))
+	}
+	return Input{
+		Hook:       hook,
+		Executable: plan.Executable,
+		Args:       plan.Args,
+		Dir:        plan.Dir,
+		Env:        env,
+		Generated:  plan.Generated,
+	}
+}
+
+// WithSession adds the outcome of a supervised session for post-exit hooks
+func (in Input) WithSession(s launcher.Session) Input {
+	code := s.ExitCode
+	in.ExitCode = &code
+	in.DurationMs = s.Duration.Milliseconds()
+	return in
+}
+
+// Find returns the executable hook files with the given name, user hook
+// first: ~/.claude/launcher/hooks/<name> and .claude/launcher/hooks/<name>
+func Find(name string) ([]string, error) {
+	var paths []string
+	for _, global := range []bool{true, false} {
+		dir, err := config.LauncherDir(global)
+		if err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, "hooks", name)
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+		}
+		if info.IsDir() || info.Mode()&0o111 == 0 {
+			return nil, fmt.Errorf("hook %s is not executable (chmod +x %s)", path, path)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Run runs every hook named in.Hook in turn with in as JSON on stdin. The
+// output of successful hooks is copied to stderr. The first hook exiting
+// non-zero stops the run; its output becomes the error message.
+func Run(in Input) error {
+	paths, err := Find(in.Hook)
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode hook input: %w", err)
+	}
+
+	for _, path := range paths {
+		cmd := exec.Command(path)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Env = append(os.Environ(), "CC_LAUNCHER_HOOK="+in.Hook)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			msg := strings.TrimSpace(string(out))
+			if msg == "" {
+				msg = err.Error()
+			}
+			return fmt.Errorf("%s hook %s failed: %s", in.Hook, path, msg)
+		}
+		if len(out) > 0 {
+			os.Stderr.Write(out)
+		}
+	}
+	return nil
+}
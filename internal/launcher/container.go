@@ -0,0 +1,141 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"cc-launcher/internal/config"
+)
+
+// lookupRuntime returns the path of the container runtime for c. Without
+// an explicit runtime docker is preferred over podman.
+func lookupRuntime(c *config.Container) (string, error) {
+	if c.Runtime != "" {
+		return exec.LookPath(c.Runtime)
+	}
+	path, err := exec.LookPath("docker")
+	if err == nil {
+		return path, nil
+	}
+	return exec.LookPath("podman")
+}
+
+// ContainerRun describes the container a plan runs in. The container
+// executor turns the plan into a runtime invocation when it runs.
+type ContainerRun struct {
+	config.Container
+	// RuntimePath is the container runtime found when planning
+	RuntimePath string `json:"runtimePath"`
+	// Files are mounted read-only, AddDirs read-write
+	Files   []string `json:"files,omitempty"`
+	AddDirs []string `json:"addDirs,omitempty"`
+	// Supervise runs the container runtime as a child, see ExecutorChild
+	Supervise bool `json:"supervise,omitempty"`
+}
+
+// containerExecutor runs a plan inside the container it describes
+type containerExecutor struct{}
+
+func (containerExecutor) Execute(plan LaunchPlan) (Session, error) {
+	if plan.Container == nil {
+		plan.RemoveGenerated()
+		return Session{}, fmt.Errorf("the %s executor needs a plan for a container launch target", ExecutorContainer)
+	}
+	supervise := plan.Container.Supervise
+	run, err := containerize(plan)
+	if err != nil {
+		plan.RemoveGenerated()
+		return Session{}, err
+	}
+	if supervise {
+		return childExecutor{}.Execute(run)
+	}
+	return execExecutor{}.Execute(run)
+}
+
+// containerize returns the plan running the plan's argv inside its
+// container. The project directory is bind-mounted at the same path and
+// used as the working directory; the additional directories are
+// bind-mounted as well. HOME is a scratch tmpfs at the host's home path,
+// into which the Claude login is mounted read-only, and files such as the
+// MCP configurations are mounted read-only as well. The plan's variables
+// are passed into the container by name so their values do not appear in
+// the argv.
+func containerize(plan LaunchPlan) (LaunchPlan, error) {
+	c := plan.Container
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	runtime := filepath.Base(c.RuntimePath)
+	uid, gid := strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid())
+
+	args := []string{runtime, "run", "--rm", "-it"}
+	if runtime == "podman" {
+		// Rootless podman maps the host user into the container this way
+		args = append(args, "--userns=keep-id")
+	} else {
+		args = append(args, "--user", uid+":"+gid)
+	}
+	args = append(args,
+		"--tmpfs", homeDir+":exec,uid="+uid+",gid="+gid,
+		"-e", "HOME="+homeDir,
+		"-v", plan.Dir+":"+plan.Dir,
+		"-w", plan.Dir,
+	)
+	for _, dir := range c.AddDirs {
+		args = append(args, "-v", dir+":"+dir)
+	}
+
+	configDir := plan.Env["CLAUDE_CONFIG_DIR"]
+	if configDir == "" {
+		configDir = filepath.Join(homeDir, ".claude")
+	}
+	readOnly := []string{
+		filepath.Join(configDir, ".credentials.json"),
+		filepath.Join(homeDir, ".claude.json"),
+	}
+	for _, file := range c.Files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return LaunchPlan{}, fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+		readOnly = append(readOnly, abs)
+	}
+	for _, path := range readOnly {
+		if _, err := os.Stat(path); err == nil {
+			args = append(args, "-v", path+":"+path+":ro")
+		}
+	}
+	for _, mount := range c.Mounts {
+		args = append(args, "-v", config.ExpandHome(mount))
+	}
+
+	if c.Network != "" {
+		args = append(args, "--network", c.Network)
+	}
+
+	names := make([]string, 0, len(plan.Env))
+	for name := range plan.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-e", name)
+	}
+
+	args = append(args, c.Args...)
+	args = append(args, c.Image, plan.Executable)
+	// The argv built for the host starts with the executable's base name
+	args = append(args, plan.Args[1:]...)
+
+	plan.Executable = c.RuntimePath
+	plan.Args = args
+	plan.Container = nil
+	return plan, nil
+}
@@ -0,0 +1,68 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"cc-launcher/internal/config"
+)
+
+func TestPlanContainerUsesContainerExecutor(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "podman"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	target := config.Target{Name: "box", Executable: "claude", Container: &config.Container{Image: "claude:latest", Runtime: "podman"}}
+
+	plan, err := Plan(Options{Targets: []config.Target{target}, Model: "opus"})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if plan.Executor != ExecutorContainer || plan.Container == nil {
+		t.Fatalf("Executor = %q, Container = %v, want a plan for the container executor", plan.Executor, plan.Container)
+	}
+	if plan.Executable != "claude" || !slices.Contains(plan.Args, "opus") {
+		t.Errorf("plan runs %s %q, want claude with the chosen model", plan.Executable, plan.Args)
+	}
+
+	for _, name := range []string{ExecutorExec, ExecutorChild} {
+		executor, _ := ExecutorFor(name)
+		if _, err := executor.Execute(plan); err == nil || !strings.Contains(err.Error(), ExecutorContainer) {
+			t.Errorf("%s executor ran a container plan on the host (err: %v)", name, err)
+		}
+	}
+}
+
+func TestContainerize(t *testing.T) {
+	plan := LaunchPlan{
+		Executable: "claude",
+		Args:       []string{"claude", "--model", "opus"},
+		Env:        map[string]string{"ANTHROPIC_AUTH_TOKEN": "secret-token"},
+		Dir:        "/src/project",
+		Container: &ContainerRun{
+			Container:   config.Container{Image: "claude:latest", Network: "none"},
+			RuntimePath: "/usr/bin/docker",
+			AddDirs:     []string{"/src/shared"},
+		},
+	}
+	run, err := containerize(plan)
+	if err != nil {
+		t.Fatalf("containerize: %v", err)
+	}
+	if run.Executable != "/usr/bin/docker" || run.Container != nil {
+		t.Errorf("Executable = %s, Container = %v, want docker without the container section", run.Executable, run.Container)
+	}
+	argv := strings.Join(run.Args, " ")
+	for _, want := range []string{"docker run --rm -it", "-v /src/project:/src/project", "-w /src/project", "-v /src/shared:/src/shared", "--network none", "-e ANTHROPIC_AUTH_TOKEN claude:latest claude --model opus"} {
+		if !strings.Contains(argv, want) {
+			t.Errorf("argv %q lacks %q", argv, want)
+		}
+	}
+	if strings.Contains(argv, "secret-token") {
+		t.Error("argv contains the value of an environment variable")
+	}
+}
@@ -0,0 +1,103 @@
+package launcher
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"cc-launcher/internal/shellquote"
+	"cc-launcher/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// secretMarkers are substrings of environment variable names whose values
+// are masked in dry-run output
+var secretMarkers = []string{"KEY", "TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "AUTH"}
+
+// EnvDelta returns the plan's variables that are new or differ from the
+// current process environment, in "NAME=value" form sorted by name
+func EnvDelta(plan LaunchPlan) []string {
+	current := envMap(os.Environ())
+
+	var delta []string
+	for name, value := range plan.Env {
+		if old, ok := current[name]; !ok || old != value {
+			delta = append(delta, name+"="+value)
+		}
+	}
+	sort.Strings(delta)
+	return delta
+}
+
+// MaskEnv replaces the value of secret-looking variables with asterisks
+func MaskEnv(entry string) string {
+	name, value, _ := strings.Cut(entry, "=")
+	if value == "" || !isSecretName(name) {
+		return entry
+	}
+	return name + "=********"
+}
+
+// PrintDryRun writes a description of the plan to w: the executable,
+// the full argv, the container or tmux panes, the working directory and the
+// environment changes. Secret values are masked.
+func PrintDryRun(w io.Writer, plan LaunchPlan) {
+	labelStyle := lipgloss.NewStyle().Foreground(ui.SecondaryColor).Bold(true)
+	title := ui.CreateGradientText("🔍 Claude Code Launcher – dry run", ui.PurpleGradientStart, ui.PurpleGradientEnd)
+	fmt.Fprintln(w, title)
+
+	fmt.Fprintf(w, "%s %s\n", labelStyle.Render("Executable: "), plan.Executable)
+	fmt.Fprintf(w, "%s %s\n", labelStyle.Render("Arguments:  "), shellquote.Join(plan.Args))
+	if plan.Container != nil {
+		fmt.Fprintf(w, "%s %s with %s\n", labelStyle.Render("Container:  "), plan.Container.Image, plan.Container.RuntimePath)
+	}
+	if plan.Tmux != nil {
+		fmt.Fprintf(w, "%s %s\n", labelStyle.Render("Session:    "), plan.Tmux.Session)
+		fmt.Fprintf(w, "%s\n", labelStyle.Render("Panes:      "))
+		for _, pane := range plan.Tmux.Panes {
+			fmt.Fprintf(w, "  %s: %s (in %s)\n", pane.Label, pane.Command, pane.Dir)
+		}
+	}
+	fmt.Fprintf(w, "%s %s\n", labelStyle.Render("Directory:  "), plan.Dir)
+	fmt.Fprintf(w, "%s\n", labelStyle.Render("Environment:"))
+
+	delta := EnvDelta(plan)
+	if len(delta) == 0 {
+		fmt.Fprintln(w, "  (unchanged)")
+	}
+	for _, entry := range delta {
+		fmt.Fprintf(w, "  %s\n", MaskEnv(entry))
+	}
+
+	if plan.Checkpoint {
+		fmt.Fprintf(w, "%s %s\n", labelStyle.Render("Checkpoint: "), "recorded before launching")
+	}
+
+	if len(plan.Generated) > 0 {
+		fmt.Fprintf(w, "%s\n", labelStyle.Render("Generated:  "))
+		for _, path := range plan.Generated {
+			fmt.Fprintf(w, "  %s\n", path)
+		}
+	}
+}
+
+func isSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		m[name] = value
+	}
+	return m
+}
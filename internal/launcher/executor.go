@@ -0,0 +1,89 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// Executor names
+const (
+	// ExecutorExec replaces the launcher process with Claude Code
+	ExecutorExec = "exec"
+	// ExecutorChild runs Claude Code as a supervised child process
+	ExecutorChild = "child"
+	// ExecutorTmux opens the panes of a tmux launch target
+	ExecutorTmux = "tmux"
+	// ExecutorContainer runs Claude Code in the container of a container
+	// launch target
+	ExecutorContainer = "container"
+)
+
+// Executor runs a LaunchPlan
+type Executor interface {
+	// Execute runs the plan. Executors that replace the launcher process
+	// only return on failure.
+	Execute(plan LaunchPlan) (Session, error)
+}
+
+var executors = map[string]Executor{
+	ExecutorExec:      execExecutor{},
+	ExecutorChild:     childExecutor{},
+	ExecutorTmux:      tmuxExecutor{},
+	ExecutorContainer: containerExecutor{},
+}
+
+// ExecutorFor returns the executor registered under name. An empty name
+// means ExecutorExec.
+func ExecutorFor(name string) (Executor, error) {
+	if name == "" {
+		name = ExecutorExec
+	}
+	e, ok := executors[name]
+	if !ok {
+		names := make([]string, 0, len(executors))
+		for n := range executors {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown executor %s (available: %s)", name, strings.Join(names, ", "))
+	}
+	return e, nil
+}
+
+// execExecutor replaces the current process using syscall.Exec. Plans with
+// generated files run as a child instead, because nothing would be left to
+// remove the files, which may hold secrets, once the process is replaced.
+type execExecutor struct{}
+
+func (execExecutor) Execute(plan LaunchPlan) (Session, error) {
+	if err := checkHostPlan(plan); err != nil {
+		plan.RemoveGenerated()
+		return Session{}, err
+	}
+	if len(plan.Generated) > 0 {
+		return childExecutor{}.Execute(plan)
+	}
+	if err := os.Chdir(plan.Dir); err != nil {
+		plan.RemoveGenerated()
+		return Session{}, fmt.Errorf("failed to change to %s: %w", plan.Dir, err)
+	}
+
+	// Use syscall.Exec to replace current process with Claude Code
+	err := syscall.Exec(plan.Executable, plan.Args, plan.Environ())
+	plan.RemoveGenerated()
+	return Session{}, err
+}
+
+// checkHostPlan refuses plans that only a more specific executor can run
+func checkHostPlan(plan LaunchPlan) error {
+	switch {
+	case plan.Tmux != nil:
+		return fmt.Errorf("the plan opens tmux panes and needs the %s executor", ExecutorTmux)
+	case plan.Container != nil:
+		return fmt.Errorf("the plan runs in a container and needs the %s executor", ExecutorContainer)
+	}
+	return nil
+}
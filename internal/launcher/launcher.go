@@ -4,163 +4,398 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"syscall"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
+	"cc-launcher/internal/claudecli"
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/preflight"
 	"cc-launcher/internal/ui"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// LaunchClaudeCode launches Claude Code with the specified MCP configuration files
-func LaunchClaudeCode(selected map[int]struct{}, mcpFiles []string, yolo bool, happy bool, resume bool, continueFlag bool, zai bool) error {
-	var executablePath, executableName string
-	
-	// Check if happy flag is set and happy is available
-	if happy {
-		happyPath, err := exec.LookPath("happy")
-		if err != nil {
-			// Happy not found, show warning and fall back to claude
-			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: 'happy' command not found in PATH. Falling back to 'claude'."))
-			claudePath, err := exec.LookPath("claude")
-			if err != nil {
-				return fmt.Errorf("claude executable not found: %w", err)
-			}
-			executablePath = claudePath
-			executableName = "claude"
-		} else {
-			executablePath = happyPath
-			executableName = "happy"
+// Options describes how Claude Code should be launched
+type Options struct {
+	// MCPFiles are the MCP configuration files to load. When empty Claude Code
+	// is launched without any MCP servers.
+	MCPFiles []string
+	Yolo     bool
+	Resume   bool
+	Continue bool
+	// Targets is the launch target followed by its fallbacks; the first
+	// whose executable is found is used. When empty, claude is launched.
+	Targets []config.Target
+	// Provider is the API endpoint profile to use; the zero value talks to
+	// Anthropic directly
+	Provider config.Provider
+	// Account selects the Claude login through CLAUDE_CONFIG_DIR; the zero
+	// value keeps the inherited configuration directory
+	Account config.Account
+	// Model is passed as --model when set
+	Model string
+	// AppendSystemPrompt is passed as --append-system-prompt when set
+	AppendSystemPrompt string
+	// SettingsFile is passed as --settings when set
+	SettingsFile string
+	// PermissionMode is passed as --permission-mode when set
+	PermissionMode string
+	// AllowedTools and DisallowedTools are passed as --allowedTools and
+	// --disallowedTools when not empty
+	AllowedTools    []string
+	DisallowedTools []string
+	// AddDirs are absolute directories passed as --add-dir. Sandboxed
+	// targets make them writable, containers mount them.
+	AddDirs []string
+	// Env holds additional environment variables
+	Env map[string]string
+	// ExtraArgs are passed to Claude Code verbatim
+	ExtraArgs []string
+	// Supervise runs Claude Code as a child process instead of replacing the
+	// launcher, see ExecutorChild
+	Supervise bool
+	// CLI describes the installed claude binary. Options it does not support
+	// are left out or warned about; nil assumes everything is supported.
+	CLI *claudecli.Info
+	// RequireSandbox refuses Yolo unless the resolved target is sandboxed
+	RequireSandbox bool
+	// Checkpoint records a git checkpoint before launching, see
+	// LaunchPlan.Checkpoint
+	Checkpoint bool
+}
+
+// managedFlags are the Claude Code flags the launcher sets itself. Passing
+// them again through ExtraArgs is allowed but usually a mistake.
+var managedFlags = []string{
+	"--dangerously-skip-permissions",
+	"--resume", "-r",
+	"--continue", "-c",
+	"--strict-mcp-config",
+	"--mcp-config",
+}
+
+// SkipsPermissions reports whether Claude Code will act without asking,
+// either with yolo or in the bypassPermissions mode, including when
+// ExtraArgs ask for either
+func (o Options) SkipsPermissions() bool {
+	return o.Yolo || o.PermissionMode == config.BypassPermissions || config.SkipPermissionsFlag(o.ExtraArgs) != ""
+}
+
+// optionalFlags are set by the launcher only when the matching option is
+// chosen, and only conflict with ExtraArgs then
+var optionalFlags = []string{"--model", "--append-system-prompt", "--settings", "--permission-mode", "--allowedTools", "--disallowedTools", "--add-dir"}
+
+// Plan is the planner: it resolves the executable and builds the argument
+// list and environment for the given options without launching anything
+func Plan(opts Options) (LaunchPlan, error) {
+	target, executablePath, err := resolveTarget(opts.Targets)
+	if err != nil {
+		return LaunchPlan{}, err
+	}
+	// Checked on the resolved target so a fallback cannot escape the sandbox
+	if opts.SkipsPermissions() && opts.RequireSandbox && target.Project && (target.Container != nil || target.Sandbox != nil) {
+		return LaunchPlan{}, fmt.Errorf("skipping permission checks requires a sandboxed launch target defined in your own settings, but %s comes from the project settings", target.Name)
+	}
+	if opts.SkipsPermissions() && opts.RequireSandbox && !target.Sandboxed() {
+		return LaunchPlan{}, fmt.Errorf("skipping permission checks requires a sandboxed launch target, but %s runs on the host", target.Name)
+	}
+	// The panes run the launcher again with their own options
+	if target.Tmux != nil {
+		if names := opts.paneOptions(); len(names) > 0 {
+			return LaunchPlan{}, fmt.Errorf("launch target %s runs the launcher in tmux panes and cannot apply %s; set them in the panes' presets or args instead", target.Name, strings.Join(names, ", "))
 		}
-	} else {
-		// Find the full path to claude executable
-		claudePath, err := exec.LookPath("claude")
+		dir, err := os.Getwd()
 		if err != nil {
-			return fmt.Errorf("claude executable not found: %w", err)
+			return LaunchPlan{}, fmt.Errorf("failed to get working directory: %w", err)
 		}
-		executablePath = claudePath
-		executableName = "claude"
+		return tmuxPlan(target, executablePath, dir)
+	}
+
+	// The installed claude says nothing about the one inside a container
+	cli := opts.CLI
+	if target.Container != nil {
+		cli = nil
 	}
 
 	// Build arguments array
-	args := []string{executableName}
+	args := []string{filepath.Base(target.Executable)}
+	args = append(args, target.Args...)
 
 	// Add --dangerously-skip-permissions if yolo flag is set
-	if yolo {
+	if opts.Yolo {
 		args = append(args, "--dangerously-skip-permissions")
 	}
 
 	// Add --resume if resume flag is set
-	if resume {
+	if opts.Resume {
 		args = append(args, "--resume")
 	}
 
 	// Add --continue if continue flag is set
-	if continueFlag {
+	if opts.Continue {
 		args = append(args, "--continue")
 	}
 
-	// Always add --strict-mcp-config to ensure only specified MCP servers are used
-	args = append(args, "--strict-mcp-config")
+	// Add --model if a model was chosen
+	if opts.Model != "" {
+		args = append(args, "--model", opts.Model)
+	}
 
-	// If "No mcp servers" is selected, only add --strict-mcp-config (no --mcp-config)
-	if _, noMcpSelected := selected[0]; !noMcpSelected {
-		args = append(args, "--mcp-config")
+	// Add --append-system-prompt if prompt fragments were chosen
+	if opts.AppendSystemPrompt != "" {
+		args = append(args, "--append-system-prompt", opts.AppendSystemPrompt)
+	}
 
-		// Add all selected MCP files
-		for i := range selected {
-			if i > 0 && i-1 < len(mcpFiles) {
-				args = append(args, mcpFiles[i-1])
+	// Add --settings if a settings variant was chosen
+	if opts.SettingsFile != "" {
+		args = append(args, "--settings", opts.SettingsFile)
+	}
+
+	// Extra arguments go before the list and MCP flags because those
+	// take a variable number of values and would swallow a trailing prompt
+	if conflicts := ManagedFlagConflicts(opts); len(conflicts) > 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: extra arguments override launcher-managed flags: "+strings.Join(conflicts, ", ")))
+	}
+	args = append(args, opts.ExtraArgs...)
+
+	if opts.PermissionMode != "" {
+		args = append(args, "--permission-mode", opts.PermissionMode)
+	}
+	if len(opts.AllowedTools) > 0 {
+		args = append(append(args, "--allowedTools"), opts.AllowedTools...)
+	}
+	if len(opts.DisallowedTools) > 0 {
+		args = append(append(args, "--disallowedTools"), opts.DisallowedTools...)
+	}
+	if len(opts.AddDirs) > 0 {
+		args = append(append(args, "--add-dir"), opts.AddDirs...)
+	}
+
+	// Always add --strict-mcp-config to ensure only specified MCP servers
+	// are used, unless the installed claude is too old to know it
+	if cli == nil || cli.Supports("--strict-mcp-config") {
+		args = append(args, "--strict-mcp-config")
+	}
+
+	// Without MCP files only --strict-mcp-config is passed (no --mcp-config)
+	var generated, mcpFiles []string
+	if len(opts.MCPFiles) > 0 {
+		args = append(args, "--mcp-config")
+		for _, file := range opts.MCPFiles {
+			resolved, isGenerated, err := config.ResolveMCPFile(file)
+			if err != nil {
+				removeFiles(generated)
+				return LaunchPlan{}, err
+			}
+			if isGenerated {
+				generated = append(generated, resolved)
 			}
+			mcpFiles = append(mcpFiles, resolved)
+			args = append(args, resolved)
 		}
 	}
 
-	// Prepare environment variables
-	env := os.Environ()
-	if zai {
-		zaiAPIKey := os.Getenv("Z_AI_API_KEY")
-		if zaiAPIKey != "" {
-			env = append(env, "ANTHROPIC_BASE_URL=https://api.z.ai/api/anthropic")
-			env = append(env, "ANTHROPIC_AUTH_TOKEN="+zaiAPIKey)
+	// An old claude exits with an unhelpful error on options it does not
+	// know, so point them out before launching
+	if cli != nil {
+		if unsupported := cli.Unsupported(launcherFlags(args)); len(unsupported) > 0 {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: claude "+cli.Version+" does not support "+strings.Join(unsupported, ", ")))
 		}
 	}
 
-	// Use syscall.Exec to replace current process with Claude Code
-	return syscall.Exec(executablePath, args, env)
-}
-
-// LaunchClaudeCodeWithoutMCP launches Claude Code without any MCP servers
-func LaunchClaudeCodeWithoutMCP(yolo bool, happy bool, resume bool, continueFlag bool, zai bool) error {
-	var executablePath, executableName string
-	
-	// Check if happy flag is set and happy is available
-	if happy {
-		happyPath, err := exec.LookPath("happy")
+	// Prepare environment variables; later sources win. The provider's
+	// token is also recorded by reference so a saved plan can leave it out.
+	env := make(map[string]string)
+	secrets := make(map[string]string)
+	mergeEnv(env, target.Env)
+	if opts.Provider.Name != "" {
+		providerEnv, err := opts.Provider.LaunchEnv()
 		if err != nil {
-			// Happy not found, show warning and fall back to claude
-			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: 'happy' command not found in PATH. Falling back to 'claude'."))
-			claudePath, err := exec.LookPath("claude")
-			if err != nil {
-				return fmt.Errorf("claude executable not found: %w", err)
-			}
-			executablePath = claudePath
-			executableName = "claude"
-		} else {
-			executablePath = happyPath
-			executableName = "happy"
+			removeFiles(generated)
+			return LaunchPlan{}, err
 		}
-	} else {
-		// Find the full path to claude executable
-		claudePath, err := exec.LookPath("claude")
+		mergeEnv(env, providerEnv)
+		if ref := opts.Provider.TokenRef(); ref != "" {
+			secrets["ANTHROPIC_AUTH_TOKEN"] = ref
+		}
+	}
+	if opts.Account.Name != "" {
+		accountEnv, err := opts.Account.LaunchEnv()
 		if err != nil {
-			return fmt.Errorf("claude executable not found: %w", err)
+			removeFiles(generated)
+			return LaunchPlan{}, err
 		}
-		executablePath = claudePath
-		executableName = "claude"
+		mergeEnv(env, accountEnv)
+		dropOverridden(secrets, accountEnv)
 	}
+	mergeEnv(env, opts.Env)
+	dropOverridden(secrets, opts.Env)
 
-	// Build arguments array
-	args := []string{executableName}
+	dir, err := os.Getwd()
+	if err != nil {
+		removeFiles(generated)
+		return LaunchPlan{}, fmt.Errorf("failed to get working directory: %w", err)
+	}
 
-	// Add --dangerously-skip-permissions if yolo flag is set
-	if yolo {
-		args = append(args, "--dangerously-skip-permissions")
+	// Generated files may hold secrets and must be removed after the session
+	executor := ExecutorExec
+	if opts.Supervise || len(generated) > 0 {
+		executor = ExecutorChild
 	}
 
-	// Add --resume if resume flag is set
-	if resume {
-		args = append(args, "--resume")
+	plan := LaunchPlan{
+		Version:    PlanVersion,
+		Executable: executablePath,
+		Args:       args,
+		Env:        env,
+		Secrets:    secrets,
+		Dir:        dir,
+		Generated:  generated,
+		Executor:   executor,
+		Checkpoint: opts.Checkpoint,
+	}
+	// The container executor wraps the argv when it runs
+	if target.Container != nil {
+		plan.Executable = target.Executable
+		plan.Executor = ExecutorContainer
+		plan.Container = &ContainerRun{
+			Container:   *target.Container,
+			RuntimePath: executablePath,
+			Files:       readOnlyFiles(mcpFiles, opts.SettingsFile),
+			AddDirs:     opts.AddDirs,
+			Supervise:   executor == ExecutorChild,
+		}
+	}
+	if target.Sandbox != nil {
+		plan, err = sandboxize(plan, target, opts.AddDirs)
+		if err != nil {
+			removeFiles(generated)
+			return LaunchPlan{}, err
+		}
 	}
+	return plan, nil
+}
 
-	// Add --continue if continue flag is set
-	if continueFlag {
-		args = append(args, "--continue")
+// readOnlyFiles returns the files a container needs to read: the MCP files
+// and the settings file, if any
+func readOnlyFiles(mcpFiles []string, settingsFile string) []string {
+	if settingsFile == "" {
+		return mcpFiles
 	}
+	return append(slices.Clone(mcpFiles), settingsFile)
+}
 
-	// Always add --strict-mcp-config to ensure no MCP servers are loaded
-	args = append(args, "--strict-mcp-config")
+// mergeEnv copies src into dst
+func mergeEnv(dst, src map[string]string) {
+	for name, value := range src {
+		dst[name] = value
+	}
+}
+
+// resolveTarget returns the first target in chain whose executable is
+// found, together with the executable's full path
+func resolveTarget(chain []config.Target) (config.Target, string, error) {
+	if len(chain) == 0 {
+		chain = []config.Target{{Name: config.DefaultTarget, Executable: "claude"}}
+	}
 
-	// Prepare environment variables
-	env := os.Environ()
-	if zai {
-		zaiAPIKey := os.Getenv("Z_AI_API_KEY")
-		if zaiAPIKey != "" {
-			env = append(env, "ANTHROPIC_BASE_URL=https://api.z.ai/api/anthropic")
-			env = append(env, "ANTHROPIC_AUTH_TOKEN="+zaiAPIKey)
+	var tried []string
+	for i, t := range chain {
+		// Container targets need the container runtime on the host
+		var path string
+		var err error
+		switch {
+		case t.Container != nil:
+			path, err = lookupRuntime(t.Container)
+		case t.Tmux != nil:
+			path, err = exec.LookPath("tmux")
+		default:
+			path, err = exec.LookPath(t.Executable)
+		}
+		if err == nil {
+			return t, path, nil
+		}
+		tried = append(tried, t.Executable)
+		if i+1 < len(chain) {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(fmt.Sprintf("Warning: '%s' command not found in PATH. Falling back to '%s'.", t.Executable, chain[i+1].Command())))
 		}
 	}
+	return config.Target{}, "", fmt.Errorf("no executable found for launch target %s (tried %s)", chain[0].Name, strings.Join(tried, ", "))
+}
 
-	// Use syscall.Exec to replace current process with Claude Code
-	return syscall.Exec(executablePath, args, env)
+// dropOverridden removes the secret references of variables set by env,
+// whose values then come from the configuration instead
+func dropOverridden(secrets, env map[string]string) {
+	for name := range env {
+		delete(secrets, name)
+	}
+}
+
+// removeFiles deletes generated files, ignoring errors
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		os.Remove(path)
+	}
 }
 
-// ShowNoMCPMessage displays a styled message when no MCP files are found
-func ShowNoMCPMessage(happy bool) {
+// launcherFlags returns the flags in args that the launcher manages
+func launcherFlags(args []string) []string {
+	var flags []string
+	for _, arg := range args {
+		name, _, _ := strings.Cut(arg, "=")
+		if (slices.Contains(managedFlags, name) || slices.Contains(optionalFlags, name)) && strings.HasPrefix(name, "--") && !slices.Contains(flags, name) {
+			flags = append(flags, name)
+		}
+	}
+	return flags
+}
+
+// ManagedFlagConflicts returns the extra arguments that repeat a flag the
+// launcher manages itself, such as --resume or --mcp-config. The
+// optionalFlags only conflict when their option was chosen.
+func ManagedFlagConflicts(opts Options) []string {
+	managed := slices.Clone(managedFlags)
+	chosen := []bool{opts.Model != "", opts.AppendSystemPrompt != "", opts.SettingsFile != "", opts.PermissionMode != "", len(opts.AllowedTools) > 0, len(opts.DisallowedTools) > 0, len(opts.AddDirs) > 0}
+	for i, flag := range optionalFlags {
+		if chosen[i] {
+			managed = append(managed, flag)
+		}
+	}
+
+	var conflicts []string
+	for _, arg := range opts.ExtraArgs {
+		name, _, _ := strings.Cut(arg, "=")
+		if slices.Contains(managed, name) {
+			conflicts = append(conflicts, arg)
+		}
+	}
+	return conflicts
+}
+
+// appendEnv appends vars to env in name order so the result is deterministic
+func appendEnv(env []string, vars map[string]string) []string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env = append(env, name+"="+vars[name])
+	}
+	return env
+}
+
+// ShowNoMCPMessage displays a styled message when no MCP files are found.
+// target is the chosen launch target, "" for the default.
+func ShowNoMCPMessage(target string) {
 	title := ui.CreateGradientText("⚡ Claude Code Launcher", ui.PurpleGradientStart, ui.PurpleGradientEnd)
 	fmt.Println(title)
 
-	// Show confirmation if happy flag is set
-	if happy {
-		happyStyle := lipgloss.NewStyle().Foreground(ui.MutedColor)
-		fmt.Println(happyStyle.Render("🎉 Happy mode enabled - will use 'happy' command instead of 'claude'"))
+	// Show confirmation if another launch target is chosen
+	if target != "" && target != config.DefaultTarget {
+		targetStyle := lipgloss.NewStyle().Foreground(ui.MutedColor)
+		fmt.Println(targetStyle.Render("🎯 Launch target '" + target + "' will be used instead of '" + config.DefaultTarget + "'"))
 	}
 	fmt.Println()
 	noMcpStyle := lipgloss.NewStyle().
@@ -178,15 +413,34 @@ func ShowNoMCPMessage(happy bool) {
 	fmt.Println()
 }
 
-// ShowLaunchMessage displays a styled message when launching Claude Code
-func ShowLaunchMessage(happy bool) {
+// ShowLaunchMessage displays a styled message when launching Claude Code.
+// target is the chosen launch target, "" for the default.
+func ShowLaunchMessage(target string) {
 	var launchMsg string
-	if happy {
-		launchMsg = ui.CreateGradientText("🚀 Launching Claude Code (with happy)...", ui.PurpleGradientStart, ui.PurpleGradientEnd)
+	if target != "" && target != config.DefaultTarget {
+		launchMsg = ui.CreateGradientText("🚀 Launching Claude Code (with "+target+")...", ui.PurpleGradientStart, ui.PurpleGradientEnd)
 	} else {
 		launchMsg = ui.CreateGradientText("🚀 Launching Claude Code...", ui.PurpleGradientStart, ui.PurpleGradientEnd)
 	}
 	fmt.Println()
 	fmt.Println(launchMsg)
 	fmt.Println()
-}
\ No newline at end of file
+}
+
+// ShowFailoverResults displays the outcome of each provider check in a
+// failover chain and which provider was chosen
+func ShowFailoverResults(results []preflight.Result) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.MutedColor)
+	chosenStyle := lipgloss.NewStyle().Foreground(ui.SuccessColor).Bold(true)
+
+	for _, r := range results {
+		if r.OK() && r.Status == 0 {
+			// No request was made, e.g. during a dry run
+			fmt.Println(chosenStyle.Render("🔀 Using provider " + r.Provider + " (not checked)"))
+		} else if r.OK() {
+			fmt.Println(chosenStyle.Render("🔀 Using provider " + r.Provider))
+		} else {
+			fmt.Println(mutedStyle.Render("⤼ Skipping " + r.Message()))
+		}
+	}
+}
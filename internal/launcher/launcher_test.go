@@ -0,0 +1,40 @@
+package launcher
+
+import (
+	"testing"
+
+	"cc-launcher/internal/config"
+)
+
+func TestOptionsSkipsPermissions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{name: "default", opts: Options{}},
+		{name: "yolo", opts: Options{Yolo: true}, want: true},
+		{name: "bypass mode", opts: Options{PermissionMode: config.BypassPermissions}, want: true},
+		{name: "plan mode", opts: Options{PermissionMode: "plan"}},
+		{name: "extra args", opts: Options{ExtraArgs: []string{"--verbose"}}},
+		{name: "extra skip flag", opts: Options{ExtraArgs: []string{"--dangerously-skip-permissions"}}, want: true},
+		{name: "extra bypass mode", opts: Options{ExtraArgs: []string{"--permission-mode", "bypassPermissions"}}, want: true},
+		{name: "extra bypass mode with =", opts: Options{ExtraArgs: []string{"--permission-mode=bypassPermissions"}}, want: true},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.SkipsPermissions(); got != tt.want {
+			t.Errorf("%s: SkipsPermissions() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRequireSandboxWithExtraArgs(t *testing.T) {
+	opts := Options{
+		Targets:        []config.Target{{Name: "sh", Executable: "sh"}},
+		ExtraArgs:      []string{"--dangerously-skip-permissions"},
+		RequireSandbox: true,
+	}
+	if _, err := Plan(opts); err == nil {
+		t.Error("Plan accepted --dangerously-skip-permissions in extra arguments on a host target")
+	}
+}
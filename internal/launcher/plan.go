@@ -0,0 +1,128 @@
+package launcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+
+	"cc-launcher/internal/config"
+)
+
+// PlanVersion is the current LaunchPlan format version
+const PlanVersion = 1
+
+// LaunchPlan is a fully resolved Claude Code invocation. Plans are built by
+// Plan, run by an Executor, and can be saved as JSON to reproduce a launch
+// exactly with `cc-launcher run`.
+type LaunchPlan struct {
+	Version    int    `json:"version"`
+	Executable string `json:"executable"`
+	// Args is the full argv, including argv[0]
+	Args []string `json:"args"`
+	// Env holds the variables set on top of the launching environment
+	Env map[string]string `json:"env,omitempty"`
+	// Secrets maps the variables of Env that hold credentials to where they
+	// come from (see config.ResolveSecretRef). Save leaves their values out
+	// and ResolveSecrets looks them up again.
+	Secrets map[string]string `json:"secrets,omitempty"`
+	Dir     string            `json:"dir"`
+	// Generated lists temporary files created for this plan, such as MCP
+	// configurations with secrets filled in
+	Generated []string `json:"generated,omitempty"`
+	// Executor names the executor that runs the plan, ExecutorExec by default
+	Executor string `json:"executor,omitempty"`
+	// Checkpoint records a git checkpoint of Dir before Claude Code starts
+	Checkpoint bool `json:"checkpoint,omitempty"`
+	// Tmux holds the panes of a plan for a tmux launch target, which only
+	// ExecutorTmux runs
+	Tmux *TmuxLayout `json:"tmux,omitempty"`
+	// Container holds the container a plan for a container launch target
+	// runs in, which only ExecutorContainer sets up
+	Container *ContainerRun `json:"container,omitempty"`
+}
+
+// Environ returns the process environment with the plan's variables applied
+func (p LaunchPlan) Environ() []string {
+	return appendEnv(os.Environ(), p.Env)
+}
+
+// RemoveGenerated deletes the plan's generated files, for when the plan
+// will not run after all
+func (p LaunchPlan) RemoveGenerated() {
+	removeFiles(p.Generated)
+}
+
+// Execute runs the plan with its executor
+func (p LaunchPlan) Execute() (Session, error) {
+	executor, err := ExecutorFor(p.Executor)
+	if err != nil {
+		return Session{}, err
+	}
+	return executor.Execute(p)
+}
+
+// ResolveSecrets looks up the credentials listed in Secrets again, for a
+// plan read with LoadPlan
+func (p LaunchPlan) ResolveSecrets() (LaunchPlan, error) {
+	if len(p.Secrets) == 0 {
+		return p, nil
+	}
+	env := maps.Clone(p.Env)
+	if env == nil {
+		env = make(map[string]string, len(p.Secrets))
+	}
+	for name, ref := range p.Secrets {
+		value, err := config.ResolveSecretRef(ref)
+		if err != nil {
+			return LaunchPlan{}, fmt.Errorf("failed to resolve %s: %w", name, err)
+		}
+		env[name] = value
+	}
+	p.Env = env
+	return p, nil
+}
+
+// Save writes the plan to path, leaving out the credentials listed in
+// Secrets. Plans with generated files are refused because those hold
+// secrets and are removed after one launch. The file is still private.
+func (p LaunchPlan) Save(path string) error {
+	if len(p.Generated) > 0 {
+		return fmt.Errorf("cannot save a launch plan with MCP configurations that reference secrets, as they would be written to disk")
+	}
+	env := make(map[string]string, len(p.Env))
+	for name, value := range p.Env {
+		if _, ok := p.Secrets[name]; !ok {
+			env[name] = value
+		}
+	}
+	p.Env = env
+
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode launch plan: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadPlan reads a plan saved with Save
+func LoadPlan(path string) (LaunchPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var p LaunchPlan
+	if err := json.Unmarshal(data, &p); err != nil {
+		return LaunchPlan{}, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if p.Version != PlanVersion {
+		return LaunchPlan{}, fmt.Errorf("unsupported launch plan version %d in %s", p.Version, path)
+	}
+	if p.Executable == "" || len(p.Args) == 0 {
+		return LaunchPlan{}, fmt.Errorf("launch plan %s has no executable", path)
+	}
+	return p, nil
+}
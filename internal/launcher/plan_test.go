@@ -0,0 +1,72 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanSaveLeavesOutSecrets(t *testing.T) {
+	t.Setenv("PLAN_TEST_TOKEN", "sk-plan-token")
+	plan := LaunchPlan{
+		Version:    PlanVersion,
+		Executable: "/usr/bin/claude",
+		Args:       []string{"claude"},
+		Env: map[string]string{
+			"ANTHROPIC_AUTH_TOKEN": "sk-plan-token",
+			"ANTHROPIC_BASE_URL":   "https://gateway.example",
+		},
+		Secrets: map[string]string{"ANTHROPIC_AUTH_TOKEN": "env:PLAN_TEST_TOKEN"},
+		Dir:     "/src/project",
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := plan.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-plan-token") {
+		t.Error("the saved plan contains the token")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("plan file mode = %v (err: %v), want 0600", info.Mode().Perm(), err)
+	}
+
+	loaded, err := LoadPlan(path)
+	if err != nil {
+		t.Fatalf("LoadPlan: %v", err)
+	}
+	if _, ok := loaded.Env["ANTHROPIC_AUTH_TOKEN"]; ok {
+		t.Error("the loaded plan has a token before ResolveSecrets")
+	}
+	if loaded.Env["ANTHROPIC_BASE_URL"] != "https://gateway.example" {
+		t.Errorf("ANTHROPIC_BASE_URL = %q, want it kept", loaded.Env["ANTHROPIC_BASE_URL"])
+	}
+	resolved, err := loaded.ResolveSecrets()
+	if err != nil {
+		t.Fatalf("ResolveSecrets: %v", err)
+	}
+	if resolved.Env["ANTHROPIC_AUTH_TOKEN"] != "sk-plan-token" {
+		t.Errorf("resolved token = %q, want it looked up again", resolved.Env["ANTHROPIC_AUTH_TOKEN"])
+	}
+}
+
+func TestPlanSaveRefusesGeneratedFiles(t *testing.T) {
+	plan := LaunchPlan{
+		Version:    PlanVersion,
+		Executable: "/usr/bin/claude",
+		Args:       []string{"claude", "--mcp-config", "/tmp/cc-launcher-mcp-123.json"},
+		Generated:  []string{"/tmp/cc-launcher-mcp-123.json"},
+	}
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := plan.Save(path); err == nil {
+		t.Error("Save accepted a plan with generated files")
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("Save wrote a plan with generated files")
+	}
+}
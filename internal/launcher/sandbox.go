@@ -0,0 +1,130 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"cc-launcher/internal/config"
+)
+
+// unshareScript runs inside the namespaces created by unshare as the mapped
+// root user. Its arguments are the caller's uid and gid, the unshare path,
+// the number of writable paths, the paths themselves and the command. The
+// writable paths are bind-mounted onto themselves first so they become
+// separate mounts, then every other mount except the pseudo filesystems is
+// remounted read-only. Finally the command runs as the caller again in a
+// nested user namespace.
+const unshareScript = `set -e
+uid=$1 gid=$2 unshare=$3 n=$4
+shift 4
+dir=$(pwd -P)
+writable=
+while [ "$n" -gt 0 ]; do
+	mount --bind "$1" "$1"
+	writable="$writable
+$1"
+	shift
+	n=$((n - 1))
+done
+while read -r _ _ _ _ point opts _; do
+	point=$(printf '%b' "$point")
+	case "$point" in /proc|/proc/*|/sys|/sys/*|/dev|/dev/*) continue ;; esac
+	case "$writable
+" in *"
+$point
+"*) continue ;; esac
+	opts=$(echo ",$opts," | sed 's/,rw,/,/; s/^,//; s/,$//')
+	mount -o "remount,bind,ro${opts:+,$opts}" "$point"
+done < /proc/self/mountinfo
+cd "$dir"
+exec "$unshare" --user --map-user="$uid" --map-group="$gid" -- "$@"
+`
+
+// lookupSandboxTool returns the path of the namespace tool for s. Without
+// an explicit tool bwrap is preferred over unshare.
+func lookupSandboxTool(s *config.Sandbox) (string, error) {
+	if s.Tool != "" {
+		return exec.LookPath(s.Tool)
+	}
+	path, err := exec.LookPath("bwrap")
+	if err == nil {
+		return path, nil
+	}
+	return exec.LookPath("unshare")
+}
+
+// sandboxize rewrites plan to run its argv inside Linux namespaces in
+// which everything but the project directory, addDirs, the scratch
+// directory and Claude Code's configuration is read-only. The scratch
+// directory is created if needed and exported as TMPDIR.
+func sandboxize(plan LaunchPlan, target config.Target, addDirs []string) (LaunchPlan, error) {
+	s := target.Sandbox
+	toolPath, err := lookupSandboxTool(s)
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("launch target %s needs bwrap or unshare: %w", target.Name, err)
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	scratch := config.ExpandHome(s.Scratch)
+	if scratch == "" {
+		scratch = filepath.Join(os.TempDir(), "cc-launcher-sandbox-"+strconv.Itoa(os.Getuid()))
+	}
+	if err := os.MkdirAll(scratch, 0o700); err != nil {
+		return LaunchPlan{}, fmt.Errorf("failed to create sandbox scratch directory: %w", err)
+	}
+	plan.Env["TMPDIR"] = scratch
+
+	// Claude Code keeps its sessions and settings in its configuration
+	configDir := plan.Env["CLAUDE_CONFIG_DIR"]
+	if configDir == "" {
+		configDir = filepath.Join(homeDir, ".claude")
+	}
+	paths := append([]string{plan.Dir, scratch, configDir, filepath.Join(homeDir, ".claude.json")}, addDirs...)
+	for _, path := range s.Writable {
+		paths = append(paths, config.ExpandHome(path))
+	}
+	var writable []string
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return LaunchPlan{}, fmt.Errorf("failed to resolve %s: %w", path, err)
+		}
+		// Bind mounts need an existing source
+		if _, err := os.Stat(abs); err == nil {
+			writable = append(writable, abs)
+		}
+	}
+
+	var args []string
+	if filepath.Base(toolPath) == "bwrap" {
+		args = []string{"bwrap", "--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--unshare-pid"}
+		if s.NoNetwork {
+			args = append(args, "--unshare-net")
+		}
+		for _, path := range writable {
+			args = append(args, "--bind", path, path)
+		}
+		args = append(args, "--chdir", plan.Dir, "--")
+	} else {
+		args = []string{"unshare", "--user", "--map-root-user", "--mount"}
+		if s.NoNetwork {
+			args = append(args, "--net")
+		}
+		args = append(args, "--", "/bin/sh", "-c", unshareScript, "sh",
+			strconv.Itoa(os.Getuid()), strconv.Itoa(os.Getgid()), toolPath, strconv.Itoa(len(writable)))
+		args = append(args, writable...)
+	}
+	// The argv built for the host starts with the executable's base name
+	args = append(args, plan.Executable)
+	args = append(args, plan.Args[1:]...)
+
+	plan.Executable = toolPath
+	plan.Args = args
+	return plan, nil
+}
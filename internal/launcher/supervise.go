@@ -0,0 +1,118 @@
+package launcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cc-launcher/internal/ui"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Session is the outcome of a supervised launch
+type Session struct {
+	Plan     LaunchPlan
+	Started  time.Time
+	Duration time.Duration
+	// ExitCode is Claude Code's exit status, or 128 plus the signal number
+	// when it was killed by a signal
+	ExitCode int
+	// Removed lists the generated files cleaned up after the session
+	Removed []string
+}
+
+// forwardedSignals are relayed to Claude Code while it runs. SIGINT and
+// SIGQUIT are caught but not relayed: the terminal already delivers them to
+// the whole foreground process group, and a second copy would look like a
+// repeated Ctrl+C to Claude Code.
+var forwardedSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP, syscall.SIGWINCH, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// childExecutor starts the plan as a child process attached to the terminal
+// instead of replacing the launcher, forwards signals and window size
+// changes to it and waits for it to exit. Generated files are removed
+// afterwards.
+type childExecutor struct{}
+
+func (childExecutor) Execute(plan LaunchPlan) (Session, error) {
+	defer plan.RemoveGenerated()
+	if err := checkHostPlan(plan); err != nil {
+		return Session{}, err
+	}
+
+	child := &exec.Cmd{
+		Path:   plan.Executable,
+		Args:   plan.Args,
+		Env:    plan.Environ(),
+		Dir:    plan.Dir,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+
+	signals := make(chan os.Signal, 8)
+	signal.Notify(signals, append([]os.Signal{os.Interrupt, syscall.SIGQUIT}, forwardedSignals...)...)
+	defer signal.Stop(signals)
+
+	session := Session{Plan: plan, Started: time.Now()}
+	if err := child.Start(); err != nil {
+		return session, fmt.Errorf("failed to start %s: %w", plan.Executable, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig == os.Interrupt || sig == syscall.SIGQUIT {
+					continue
+				}
+				child.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := child.Wait()
+	close(done)
+	session.Duration = time.Since(session.Started)
+	plan.RemoveGenerated()
+	session.Removed = plan.Generated
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return session, fmt.Errorf("failed to wait for %s: %w", plan.Executable, err)
+	}
+	session.ExitCode = exitCode(child.ProcessState)
+	return session, nil
+}
+
+// exitCode follows the shell convention of 128 plus the signal number for
+// processes killed by a signal
+func exitCode(state *os.ProcessState) int {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return state.ExitCode()
+}
+
+// ShowSessionSummary displays how a supervised session ended
+func ShowSessionSummary(s Session) {
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.MutedColor)
+	successStyle := lipgloss.NewStyle().Foreground(ui.SuccessColor).Bold(true)
+
+	fmt.Println()
+	summary := fmt.Sprintf("Session ended after %s", s.Duration.Round(time.Second))
+	if s.ExitCode == 0 {
+		fmt.Println(successStyle.Render("✅ " + summary))
+	} else {
+		fmt.Println(ui.RenderError(fmt.Sprintf("%s with exit code %d", summary, s.ExitCode)))
+	}
+	if len(s.Removed) > 0 {
+		fmt.Println(mutedStyle.Render(fmt.Sprintf("🧹 Removed %d generated file(s)", len(s.Removed))))
+	}
+}
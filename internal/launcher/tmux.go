@@ -0,0 +1,178 @@
+package launcher
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/shellquote"
+)
+
+// tmuxPaneOption is the pane option holding a pane's name. Claude Code
+// sets the pane title itself, so the border shows this option instead.
+const tmuxPaneOption = "@cc-launcher-pane"
+
+// TmuxLayout describes the panes a tmux plan opens. The tmux executor turns
+// it into tmux commands once it knows whether the session exists.
+type TmuxLayout struct {
+	Session string `json:"session"`
+	// Window names the window holding the panes unless Windows opens one
+	// window per pane
+	Window  string     `json:"window"`
+	Windows bool       `json:"windows,omitempty"`
+	Layout  string     `json:"layout,omitempty"`
+	Panes   []TmuxPane `json:"panes"`
+}
+
+// TmuxPane is one launcher session of a TmuxLayout
+type TmuxPane struct {
+	Label string `json:"label"`
+	Dir   string `json:"dir"`
+	// Command is the shell command the pane runs
+	Command string `json:"command"`
+}
+
+// tmuxPlan builds a plan that opens the panes of the target's tmux layout,
+// each running the launcher itself with the pane's arguments
+func tmuxPlan(target config.Target, tmuxPath string, dir string) (LaunchPlan, error) {
+	t := target.Tmux
+	self, err := os.Executable()
+	if err != nil {
+		return LaunchPlan{}, fmt.Errorf("failed to locate the launcher executable: %w", err)
+	}
+
+	layout := &TmuxLayout{Session: t.Session, Window: target.Name, Windows: t.Windows, Layout: t.Layout}
+	if layout.Session == "" {
+		layout.Session = config.DefaultTmuxSession(dir)
+	}
+	if layout.Layout == "" && !t.Windows {
+		layout.Layout = "tiled"
+	}
+	for _, pane := range t.Panes {
+		paneDir := dir
+		if pane.Dir != "" {
+			paneDir = config.ExpandHome(pane.Dir)
+			if !filepath.IsAbs(paneDir) {
+				paneDir = filepath.Join(dir, paneDir)
+			}
+		}
+		command := []string{self}
+		if pane.Worktree != "" {
+			command = append(command, "--worktree", pane.Worktree)
+		}
+		if pane.Preset != "" {
+			command = append(command, "--preset", pane.Preset)
+		}
+		command = append(command, pane.Args...)
+		layout.Panes = append(layout.Panes, TmuxPane{Label: pane.Label(), Dir: paneDir, Command: shellquote.Join(command)})
+	}
+
+	return LaunchPlan{
+		Version:    PlanVersion,
+		Executable: tmuxPath,
+		Args:       []string{"tmux"},
+		Env:        map[string]string{},
+		Dir:        dir,
+		Executor:   ExecutorTmux,
+		Tmux:       layout,
+	}, nil
+}
+
+// tmuxArgs returns the tmux argv opening the layout. A new session is
+// created unless it exists already or the launcher runs inside tmux; then
+// the panes go into a new window.
+func tmuxArgs(l *TmuxLayout, exists, insideTmux bool) []string {
+	var commands [][]string
+	for i, pane := range l.Panes {
+		window := l.Window
+		if l.Windows {
+			window = pane.Label
+		}
+		var open []string
+		switch {
+		case i == 0 && !exists && !insideTmux:
+			open = []string{"new-session", "-s", l.Session, "-n", window}
+		case i == 0 && insideTmux:
+			open = []string{"new-window", "-n", window}
+		case i == 0 || l.Windows:
+			open = []string{"new-window", "-t", l.Session + ":", "-n", window}
+		default:
+			open = []string{"split-window"}
+		}
+		commands = append(commands,
+			append(open, "-c", pane.Dir, pane.Command),
+			[]string{"set-option", "-p", tmuxPaneOption, pane.Label})
+	}
+
+	if !l.Windows {
+		commands = append(commands,
+			[]string{"select-layout", l.Layout},
+			[]string{"set-option", "-w", "pane-border-status", "top"},
+			[]string{"set-option", "-w", "pane-border-format", " #{" + tmuxPaneOption + "} "})
+	}
+	// Commands sent to an existing session from outside need a client
+	if exists && !insideTmux {
+		commands = append(commands, []string{"attach-session", "-t", l.Session})
+	}
+
+	// tmux runs a sequence of commands separated by ";" arguments
+	args := []string{"tmux"}
+	for i, command := range commands {
+		if i > 0 {
+			args = append(args, ";")
+		}
+		args = append(args, command...)
+	}
+	return args
+}
+
+// tmuxExecutor opens the panes of a tmux plan. Whether the session exists
+// is only checked now, so a saved plan still works after it was closed.
+type tmuxExecutor struct{}
+
+func (tmuxExecutor) Execute(plan LaunchPlan) (Session, error) {
+	if plan.Tmux == nil {
+		return Session{}, fmt.Errorf("the %s executor needs a plan for a tmux launch target", ExecutorTmux)
+	}
+	exists := exec.Command(plan.Executable, "has-session", "-t", "="+plan.Tmux.Session).Run() == nil
+	insideTmux := os.Getenv("TMUX") != ""
+
+	plan.Args = tmuxArgs(plan.Tmux, exists, insideTmux)
+	plan.Tmux = nil
+	return execExecutor{}.Execute(plan)
+}
+
+// paneOptions names the options of a single Claude Code session that are
+// set in o. The panes of a tmux target run the launcher again with their
+// own options, so a tmux plan cannot apply these.
+func (o Options) paneOptions() []string {
+	set := []struct {
+		name string
+		ok   bool
+	}{
+		{"MCP configurations", len(o.MCPFiles) > 0},
+		{"yolo", o.Yolo},
+		{"resume", o.Resume},
+		{"continue", o.Continue},
+		{"provider", o.Provider.Name != ""},
+		{"account", o.Account.Name != ""},
+		{"model", o.Model != ""},
+		{"system prompt", o.AppendSystemPrompt != ""},
+		{"settings", o.SettingsFile != ""},
+		{"permission mode", o.PermissionMode != ""},
+		{"allowed tools", len(o.AllowedTools) > 0},
+		{"disallowed tools", len(o.DisallowedTools) > 0},
+		{"additional directories", len(o.AddDirs) > 0},
+		{"environment", len(o.Env) > 0},
+		{"extra arguments", len(o.ExtraArgs) > 0},
+	}
+	var names []string
+	for _, s := range set {
+		if s.ok {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,103 @@
+package launcher
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"cc-launcher/internal/config"
+)
+
+// fakeTmux puts a tmux into PATH that only records that it ran, and
+// returns the target using it and the file it writes
+func fakeTmux(t *testing.T) (config.Target, string) {
+	t.Helper()
+	bin := t.TempDir()
+	marker := filepath.Join(bin, "ran")
+	script := "#!/bin/sh\ntouch " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "tmux"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	target := config.Target{Name: "team", Tmux: &config.Tmux{Session: "cc-test", Panes: []config.TmuxPane{
+		{Name: "api", Preset: "review"},
+		{Name: "web", Dir: "web", Args: []string{"--model", "sonnet"}},
+	}}}
+	return target, marker
+}
+
+func TestPlanTmuxRefusesPaneOptions(t *testing.T) {
+	target, _ := fakeTmux(t)
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "yolo", opts: Options{Yolo: true}},
+		{name: "mcp", opts: Options{MCPFiles: []string{"mcp.json"}}},
+		{name: "model", opts: Options{Model: "opus"}},
+		{name: "provider", opts: Options{Provider: config.Provider{Name: "proxy"}}},
+		{name: "add dirs", opts: Options{AddDirs: []string{"/tmp"}}},
+		{name: "extra args", opts: Options{ExtraArgs: []string{"--verbose"}}},
+	}
+	for _, tt := range tests {
+		tt.opts.Targets = []config.Target{target}
+		if _, err := Plan(tt.opts); err == nil {
+			t.Errorf("%s: Plan accepted an option the tmux panes cannot apply", tt.name)
+		}
+	}
+}
+
+func TestPlanTmuxDoesNotRunTmux(t *testing.T) {
+	target, marker := fakeTmux(t)
+	plan, err := Plan(Options{Targets: []config.Target{target}, Checkpoint: true})
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("planning ran tmux")
+	}
+	if plan.Executor != ExecutorTmux || plan.Tmux == nil {
+		t.Fatalf("Executor = %q, Tmux = %v, want a plan for the tmux executor", plan.Executor, plan.Tmux)
+	}
+	if plan.Checkpoint {
+		t.Error("tmux plan records a checkpoint; the panes record their own")
+	}
+	if len(plan.Tmux.Panes) != 2 || !strings.HasSuffix(plan.Tmux.Panes[1].Dir, "/web") {
+		t.Errorf("Panes = %+v", plan.Tmux.Panes)
+	}
+	if !strings.HasSuffix(plan.Tmux.Panes[0].Command, " --preset review") {
+		t.Errorf("pane command = %q, want the preset passed on", plan.Tmux.Panes[0].Command)
+	}
+}
+
+func TestTmuxArgs(t *testing.T) {
+	layout := &TmuxLayout{Session: "cc-test", Window: "team", Layout: "tiled", Panes: []TmuxPane{
+		{Label: "api", Dir: "/src/api", Command: "cc-launcher"},
+		{Label: "web", Dir: "/src/web", Command: "cc-launcher"},
+	}}
+	tests := []struct {
+		name       string
+		exists     bool
+		insideTmux bool
+		open       []string
+		attach     bool
+	}{
+		{name: "new session", open: []string{"new-session", "-s", "cc-test", "-n", "team"}},
+		{name: "existing session", exists: true, open: []string{"new-window", "-t", "cc-test:", "-n", "team"}, attach: true},
+		{name: "inside tmux", insideTmux: true, open: []string{"new-window", "-n", "team"}},
+	}
+	for _, tt := range tests {
+		args := tmuxArgs(layout, tt.exists, tt.insideTmux)
+		if !slices.Equal(args[1:1+len(tt.open)], tt.open) {
+			t.Errorf("%s: args start with %q, want %q", tt.name, args[1:1+len(tt.open)], tt.open)
+		}
+		if !slices.Contains(args, "split-window") {
+			t.Errorf("%s: second pane is not split off: %q", tt.name, args)
+		}
+		if attached := slices.Contains(args, "attach-session"); attached != tt.attach {
+			t.Errorf("%s: attach-session = %v, want %v", tt.name, attached, tt.attach)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package preflight
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"cc-launcher/internal/config"
+)
+
+// DefaultTimeout bounds a single provider check when none is configured
+const DefaultTimeout = 5 * time.Second
+
+// defaultHealthPath is requested when a provider does not set HealthPath.
+// Endpoints exposing the Anthropic API serve it, and it is cheap.
+const defaultHealthPath = "/v1/models"
+
+// Failure kinds reported in Result.Kind
+const (
+	KindDNS     = "dns"
+	KindTLS     = "tls"
+	KindTimeout = "timeout"
+	KindAuth    = "auth"
+	KindServer  = "server"
+	KindNetwork = "network"
+	KindConfig  = "config"
+)
+
+// Result is the outcome of checking a single provider
+type Result struct {
+	Provider string
+	URL      string
+	Status   int
+	Latency  time.Duration
+	// Kind classifies the failure; it is empty when the check passed
+	Kind string
+	Err  error
+}
+
+// OK reports whether the provider is reachable and accepted the credentials
+func (r Result) OK() bool {
+	return r.Err == nil
+}
+
+// Message describes the result in a single line suitable for the TUI
+func (r Result) Message() string {
+	if r.OK() {
+		return fmt.Sprintf("provider %s is healthy (%d in %s)", r.Provider, r.Status, r.Latency.Round(time.Millisecond))
+	}
+	return fmt.Sprintf("provider %s failed pre-flight check (%s): %v", r.Provider, r.Kind, r.Err)
+}
+
+// defaultClient does not follow redirects, which would send the token to
+// wherever the provider points
+var defaultClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// Checker performs provider pre-flight checks. The zero value uses a client
+// that does not follow redirects and DefaultTimeout.
+type Checker struct {
+	Client  *http.Client
+	Timeout time.Duration
+}
+
+// Check makes a lightweight authenticated GET request against the provider's
+// base URL. Any response other than 401/403 or a 5xx status counts as healthy:
+// the endpoint is reachable and did not reject the token. That includes
+// redirects, which are not followed. Providers without a token, such as
+// config.Anthropic, are only checked for reachability.
+func (c Checker) Check(ctx context.Context, p config.Provider) Result {
+	result := Result{Provider: p.Name}
+
+	if p.BaseURL == "" {
+		result.Kind, result.Err = KindConfig, errors.New("provider has no baseUrl")
+		return result
+	}
+	token, err := p.Token()
+	if err != nil {
+		result.Kind, result.Err = KindConfig, err
+		return result
+	}
+
+	path := p.HealthPath
+	if path == "" {
+		path = defaultHealthPath
+	}
+	result.URL = strings.TrimRight(p.BaseURL, "/") + path
+
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.URL, nil)
+	if err != nil {
+		result.Kind, result.Err = KindConfig, fmt.Errorf("invalid baseUrl: %w", err)
+		return result
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("x-api-key", token)
+	}
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	client := c.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Kind, result.Err = classify(err), err
+		return result
+	}
+	resp.Body.Close()
+	result.Status = resp.StatusCode
+
+	switch {
+	case token != "" && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+		result.Kind, result.Err = KindAuth, fmt.Errorf("credentials rejected: %s", resp.Status)
+	case resp.StatusCode >= 500:
+		result.Kind, result.Err = KindServer, fmt.Errorf("server error: %s", resp.Status)
+	}
+	return result
+}
+
+// SelectProvider returns the first healthy provider in chain, together with
+// the results of every check made. With a nil checker no requests are made
+// and the first provider whose credentials resolve is chosen.
+func SelectProvider(ctx context.Context, checker *Checker, chain []config.Provider) (config.Provider, []Result, error) {
+	var results []Result
+	for _, p := range chain {
+		var result Result
+		if checker != nil {
+			result = checker.Check(ctx, p)
+		} else if _, err := p.Token(); err != nil {
+			result = Result{Provider: p.Name, Kind: KindConfig, Err: err}
+		} else {
+			result = Result{Provider: p.Name}
+		}
+		results = append(results, result)
+		if result.OK() {
+			return p, results, nil
+		}
+	}
+	return config.Provider{}, results, errors.New("no healthy provider in failover chain")
+}
+
+// classify maps a transport error to a failure kind
+func classify(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindTimeout
+	case errors.As(err, &dnsErr):
+		return KindDNS
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &recordErr):
+		return KindTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return KindTimeout
+	}
+	return KindNetwork
+}
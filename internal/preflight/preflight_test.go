@@ -0,0 +1,205 @@
+package preflight
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cc-launcher/internal/config"
+)
+
+// testToken is the API token the test providers send
+const testToken = "test-token"
+
+// newServer starts a server answering every request with status and counts
+// the requests it receives
+func newServer(t *testing.T, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+// newProvider returns a provider for baseURL whose token is read from an
+// environment variable set for the test
+func newProvider(t *testing.T, name, baseURL string) config.Provider {
+	t.Helper()
+	t.Setenv("PREFLIGHT_TEST_TOKEN", testToken)
+	return config.Provider{Name: name, BaseURL: baseURL, AuthTokenEnv: "PREFLIGHT_TEST_TOKEN"}
+}
+
+func TestCheckClassifiesStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		noAuth bool
+		kind   string
+	}{
+		{name: "ok", status: http.StatusOK},
+		{name: "no content", status: http.StatusNoContent},
+		{name: "not found", status: http.StatusNotFound},
+		{name: "unauthorized", status: http.StatusUnauthorized, kind: KindAuth},
+		{name: "forbidden", status: http.StatusForbidden, kind: KindAuth},
+		{name: "unauthorized without token", status: http.StatusUnauthorized, noAuth: true},
+		{name: "internal error", status: http.StatusInternalServerError, kind: KindServer},
+		{name: "bad gateway", status: http.StatusBadGateway, kind: KindServer},
+		{name: "unavailable", status: http.StatusServiceUnavailable, kind: KindServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newServer(t, tt.status)
+			p := newProvider(t, tt.name, server.URL)
+			if tt.noAuth {
+				p.AuthTokenEnv = ""
+			}
+
+			result := Checker{}.Check(context.Background(), p)
+			if result.Status != tt.status {
+				t.Errorf("Status = %d, want %d", result.Status, tt.status)
+			}
+			if result.Kind != tt.kind {
+				t.Errorf("Kind = %q, want %q (err: %v)", result.Kind, tt.kind, result.Err)
+			}
+			if result.OK() != (tt.kind == "") {
+				t.Errorf("OK() = %v, want %v", result.OK(), tt.kind == "")
+			}
+		})
+	}
+}
+
+func TestCheckSendsCredentials(t *testing.T) {
+	// The server answers 500 when the request is not what a provider expects
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != defaultHealthPath || r.Header.Get("Authorization") != "Bearer "+testToken || r.Header.Get("x-api-key") != testToken {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	result := Checker{}.Check(context.Background(), newProvider(t, "p", server.URL+"/"))
+	if !result.OK() {
+		t.Errorf("Check failed: %s", result.Message())
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	checker := Checker{Timeout: 50 * time.Millisecond}
+	result := checker.Check(context.Background(), newProvider(t, "slow", server.URL))
+	if result.Kind != KindTimeout {
+		t.Errorf("Kind = %q, want %q (err: %v)", result.Kind, KindTimeout, result.Err)
+	}
+	if result.OK() {
+		t.Error("OK() = true for a timed out check")
+	}
+}
+
+func TestCheckWithoutBaseURL(t *testing.T) {
+	result := Checker{}.Check(context.Background(), config.Provider{Name: "p"})
+	if result.Kind != KindConfig {
+		t.Errorf("Kind = %q, want %q", result.Kind, KindConfig)
+	}
+}
+
+func TestSelectProviderFailover(t *testing.T) {
+	down, downHits := newServer(t, http.StatusServiceUnavailable)
+	rejected, rejectedHits := newServer(t, http.StatusUnauthorized)
+	healthy, healthyHits := newServer(t, http.StatusOK)
+	spare, spareHits := newServer(t, http.StatusOK)
+	chain := []config.Provider{
+		newProvider(t, "down", down.URL),
+		newProvider(t, "rejected", rejected.URL),
+		newProvider(t, "healthy", healthy.URL),
+		newProvider(t, "spare", spare.URL),
+	}
+
+	chosen, results, err := SelectProvider(context.Background(), &Checker{}, chain)
+	if err != nil {
+		t.Fatalf("SelectProvider: %v", err)
+	}
+	if chosen.Name != "healthy" {
+		t.Errorf("chose %s, want healthy", chosen.Name)
+	}
+
+	want := []struct{ provider, kind string }{{"down", KindServer}, {"rejected", KindAuth}, {"healthy", ""}}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Provider != w.provider || results[i].Kind != w.kind {
+			t.Errorf("results[%d] = %s (%q), want %s (%q)", i, results[i].Provider, results[i].Kind, w.provider, w.kind)
+		}
+	}
+
+	for name, hits := range map[string]*atomic.Int32{"down": downHits, "rejected": rejectedHits, "healthy": healthyHits} {
+		if n := hits.Load(); n != 1 {
+			t.Errorf("%s was checked %d times, want 1", name, n)
+		}
+	}
+	if n := spareHits.Load(); n != 0 {
+		t.Errorf("spare was checked %d times after a healthy provider was found", n)
+	}
+}
+
+func TestSelectProviderAllFail(t *testing.T) {
+	down, _ := newServer(t, http.StatusInternalServerError)
+	chain := []config.Provider{newProvider(t, "a", down.URL), newProvider(t, "b", down.URL)}
+
+	_, results, err := SelectProvider(context.Background(), &Checker{}, chain)
+	if err == nil {
+		t.Fatal("SelectProvider succeeded without a healthy provider")
+	}
+	if len(results) != len(chain) {
+		t.Errorf("got %d results, want %d", len(results), len(chain))
+	}
+}
+
+func TestSelectProviderWithoutChecker(t *testing.T) {
+	t.Setenv("PREFLIGHT_TEST_UNSET", "")
+	missing := config.Provider{Name: "missing", BaseURL: "http://127.0.0.1:1", AuthTokenEnv: "PREFLIGHT_TEST_UNSET"}
+	chain := []config.Provider{missing, newProvider(t, "configured", "http://127.0.0.1:1")}
+
+	chosen, results, err := SelectProvider(context.Background(), nil, chain)
+	if err != nil {
+		t.Fatalf("SelectProvider: %v", err)
+	}
+	if chosen.Name != "configured" {
+		t.Errorf("chose %s, want configured", chosen.Name)
+	}
+	if len(results) != 2 || results[0].Kind != KindConfig {
+		t.Errorf("results = %+v, want a config failure for missing first", results)
+	}
+}
+
+func TestCheckDoesNotFollowRedirects(t *testing.T) {
+	var leaked atomic.Bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "" || r.Header.Get("Authorization") != "" {
+			leaked.Store(true)
+		}
+	}))
+	defer target.Close()
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+r.URL.Path, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	result := Checker{}.Check(context.Background(), newProvider(t, "p", redirect.URL))
+	if !result.OK() || result.Status != http.StatusFound {
+		t.Errorf("Status = %d, OK() = %v, want a reachable 302", result.Status, result.OK())
+	}
+	if leaked.Load() {
+		t.Error("the redirect target received the token")
+	}
+}
@@ -0,0 +1,182 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// kdfIterations is the PBKDF2-SHA256 work factor for new files. Existing
+// files record their own count so it can be raised without breaking them.
+const kdfIterations = 600_000
+
+// ErrWrongPassphrase is returned when a store cannot be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted secrets file")
+
+// envelope is the on-disk format: a passphrase-derived AES-256-GCM key
+// encrypts the JSON encoded name/value map
+type envelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Store is a decrypted credential store. Changes are kept in memory until
+// Save is called.
+type Store struct {
+	path       string
+	passphrase []byte
+	values     map[string]string
+}
+
+// DefaultPath returns ~/.claude/launcher/secrets.enc
+func DefaultPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".claude", "launcher", "secrets.enc"), nil
+}
+
+// Exists reports whether a store file exists at path
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open decrypts the store at path. A missing file yields an empty store
+// that will be created with passphrase on Save.
+func Open(path string, passphrase []byte) (*Store, error) {
+	s := &Store{path: path, passphrase: passphrase, values: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	if env.Version != 1 || env.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported secrets file %s (version %d, kdf %q)", path, env.Version, env.KDF)
+	}
+
+	gcm, err := newGCM(passphrase, env.Salt, env.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	if err := json.Unmarshal(plaintext, &s.values); err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return s, nil
+}
+
+// Get returns the named secret
+func (s *Store) Get(name string) (string, bool) {
+	v, ok := s.values[name]
+	return v, ok
+}
+
+// Set stores a secret, replacing any previous value
+func (s *Store) Set(name, value string) {
+	s.values[name] = value
+}
+
+// Delete removes a secret and reports whether it existed
+func (s *Store) Delete(name string) bool {
+	_, ok := s.values[name]
+	delete(s.values, name)
+	return ok
+}
+
+// Names returns the stored secret names in sorted order
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Save encrypts the store with a fresh salt and nonce and writes it with
+// owner-only permissions
+func (s *Store) Save() error {
+	plaintext, err := json.Marshal(s.values)
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	env := envelope{
+		Version:    1,
+		KDF:        "pbkdf2-sha256",
+		Iterations: kdfIterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := newGCM(s.passphrase, env.Salt, env.Iterations)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets file: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(s.path), err)
+	}
+
+	// Write to a temporary file first so a failed write never truncates
+	// the existing store
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func newGCM(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, fmt.Errorf("invalid KDF iteration count %d", iterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
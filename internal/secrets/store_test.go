@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "launcher", "secrets.enc")
+	store, err := Open(path, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("Open of a missing file: %v", err)
+	}
+	store.Set("gateway", "sk-gateway-token")
+	store.Set("zai", "sk-zai-token")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-gateway-token") || strings.Contains(string(data), "gateway") {
+		t.Error("the secrets file contains a secret or its name in plain text")
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("secrets file mode = %v (err: %v), want 0600", info.Mode().Perm(), err)
+	}
+
+	reopened, err := Open(path, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if value, ok := reopened.Get("gateway"); !ok || value != "sk-gateway-token" {
+		t.Errorf("Get(gateway) = %q, %v, want the saved token", value, ok)
+	}
+	if names := reopened.Names(); len(names) != 2 || names[0] != "gateway" || names[1] != "zai" {
+		t.Errorf("Names() = %q, want gateway and zai", names)
+	}
+}
+
+func TestStoreWrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	store, err := Open(path, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Set("gateway", "sk-gateway-token")
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if _, err := Open(path, []byte("battery staple")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open with the wrong passphrase: err = %v, want ErrWrongPassphrase", err)
+	}
+}
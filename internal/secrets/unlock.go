@@ -0,0 +1,76 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// PassphraseEnv can hold the passphrase for non-interactive use
+const PassphraseEnv = "CC_LAUNCHER_PASSPHRASE"
+
+// ReadPassphrase returns the passphrase from PassphraseEnv or prompts for it
+// on the terminal without echo
+func ReadPassphrase(prompt string) ([]byte, error) {
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		return []byte(p), nil
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("no terminal to read the passphrase from (set %s): %w", PassphraseEnv, err)
+	}
+	defer tty.Close()
+
+	fmt.Fprint(tty, prompt)
+	passphrase, err := term.ReadPassword(tty.Fd())
+	fmt.Fprintln(tty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return passphrase, nil
+}
+
+// Unlocker opens the default store the first time a secret is requested,
+// so the passphrase is asked for at most once per launch
+type Unlocker struct {
+	once  sync.Once
+	store *Store
+	err   error
+}
+
+// Get returns the named secret, unlocking the store if necessary
+func (u *Unlocker) Get(name string) (string, error) {
+	if err := u.Unlock(); err != nil {
+		return "", err
+	}
+	value, ok := u.store.Get(name)
+	if !ok {
+		return "", fmt.Errorf("secret %s not found (add it with: cc-launcher secrets set %s)", name, name)
+	}
+	return value, nil
+}
+
+// Unlock opens the store now. It is a no-op after the first call.
+func (u *Unlocker) Unlock() error {
+	u.once.Do(func() {
+		path, err := DefaultPath()
+		if err != nil {
+			u.err = err
+			return
+		}
+		if !Exists(path) {
+			u.err = fmt.Errorf("no secrets stored yet (add one with: cc-launcher secrets set <name>)")
+			return
+		}
+		passphrase, err := ReadPassphrase("🔐 Secrets passphrase: ")
+		if err != nil {
+			u.err = err
+			return
+		}
+		u.store, u.err = Open(path, passphrase)
+	})
+	return u.err
+}
@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"cc-launcher/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// addDir is a configured or requested additional directory
+type addDir struct {
+	path     string
+	dir      string
+	err      error
+	selected bool
+}
+
+// WithAddDirs offers the additional directories paths, selecting those
+// that exist. The section ends with a field for one more directory.
+func (m Model) WithAddDirs(paths []string) Model {
+	m.addDirs = nil
+	for _, path := range paths {
+		dir, err := config.ResolveAddDir(path)
+		if slices.ContainsFunc(m.addDirs, func(d addDir) bool { return d.dir == dir }) {
+			continue
+		}
+		m.addDirs = append(m.addDirs, addDir{path: path, dir: dir, err: err, selected: err == nil})
+	}
+	return m
+}
+
+// AddDirs returns the absolute paths of the selected additional
+// directories, including the one typed into the field if it exists
+func (m Model) AddDirs() []string {
+	if m.addDirsDisabled() {
+		return nil
+	}
+	var dirs []string
+	for _, d := range m.addDirs {
+		if d.selected {
+			dirs = append(dirs, d.dir)
+		}
+	}
+	if dir, err := m.typedAddDir(); dir != "" && err == nil && !slices.Contains(dirs, dir) {
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// typedAddDir resolves the directory typed into the field, returning ""
+// when the field is empty
+func (m Model) typedAddDir() (string, error) {
+	path := strings.TrimSpace(m.newAddDir)
+	if path == "" {
+		return "", nil
+	}
+	return config.ResolveAddDir(path)
+}
+
+// addDirsDisabled reports whether the installed binary lacks --add-dir
+func (m Model) addDirsDisabled() bool {
+	return m.cli != nil && !m.cli.Supports("--add-dir")
+}
+
+// toggleAddDir selects or deselects the directory under the cursor unless
+// it does not exist
+func (m *Model) toggleAddDir() {
+	if m.addDirCursor < len(m.addDirs) && m.addDirs[m.addDirCursor].err == nil {
+		m.addDirs[m.addDirCursor].selected = !m.addDirs[m.addDirCursor].selected
+	}
+}
+
+// updateAddDirs lets the field take typed keys. It reports whether msg was
+// handled.
+func (m *Model) updateAddDirs(msg tea.KeyMsg) bool {
+	return m.addDirCursor == len(m.addDirs) && editText(&m.newAddDir, msg)
+}
+
+// renderAddDirs writes the additional directories section to s
+func (m Model) renderAddDirs(s *strings.Builder) {
+	if m.addDirsDisabled() {
+		s.WriteString(HeaderStyle.Render("📂 Additional directories:") + "\n")
+		s.WriteString("    " + LocationStyle.Render(m.cli.Explain("--add-dir")) + "\n")
+		return
+	}
+
+	focused := m.focus == sectionAddDirs
+	headerStyle := HeaderStyle
+	if focused {
+		headerStyle = headerStyle.Foreground(SelectedItemStyle.GetForeground())
+	}
+	s.WriteString(headerStyle.Render("📂 Additional directories:") + "\n")
+
+	for i, d := range m.addDirs {
+		cursor := " "
+		item := UnselectedItemStyle.Render(d.path)
+		if focused && m.addDirCursor == i {
+			cursor = CursorStyle.Render("❯")
+			item = SelectedItemStyle.Render(d.path)
+		}
+
+		checkbox := CheckboxUnselectedStyle.Render("⬡")
+		if d.selected {
+			checkbox = CheckboxSelectedStyle.Render("⬢")
+		}
+		switch {
+		case d.err != nil:
+			checkbox = CheckboxUnselectedStyle.Render("✗")
+			item += LocationStyle.Render(" (" + d.err.Error() + ")")
+		case d.dir != d.path:
+			item += LocationStyle.Render(" (" + d.dir + ")")
+		}
+		s.WriteString(fmt.Sprintf(" %s %s %s\n", cursor, checkbox, item))
+	}
+
+	// The field for one more directory
+	cursor := " "
+	item := UnselectedItemStyle.Render(m.newAddDir)
+	if focused && m.addDirCursor == len(m.addDirs) {
+		cursor = CursorStyle.Render("❯")
+		item = SelectedItemStyle.Render(m.newAddDir) + CursorStyle.Render("█")
+	}
+	dir, err := m.typedAddDir()
+	switch {
+	case dir == "":
+		item += LocationStyle.Render("(type a path to add a directory)")
+	case err != nil:
+		item += LocationStyle.Render(" (" + err.Error() + ")")
+	case dir != strings.TrimSpace(m.newAddDir):
+		item += LocationStyle.Render(" (" + dir + ")")
+	}
+	s.WriteString(fmt.Sprintf(" %s %s %s\n", cursor, CheckboxUnselectedStyle.Render("+"), item))
+}
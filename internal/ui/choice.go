@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// choiceList is a single-choice section of the TUI, such as the model picker.
+// The first entry is always the default whose value is empty, meaning the
+// launcher should not pass anything for this section.
+type choiceList struct {
+	labels  []string
+	values  []string
+	details []string
+	chosen  int
+	cursor  int
+}
+
+// newChoiceList builds a list with a default entry followed by values and
+// chooses current if it is one of them
+func newChoiceList(defaultLabel string, values []string, current string) choiceList {
+	c := choiceList{
+		labels:  []string{defaultLabel},
+		values:  []string{""},
+		details: []string{""},
+	}
+	for _, v := range values {
+		c.labels = append(c.labels, v)
+		c.values = append(c.values, v)
+		c.details = append(c.details, "")
+	}
+	if i := slices.Index(c.values, current); i > 0 {
+		c.chosen = i
+	}
+	return c
+}
+
+// Value returns the chosen value, or "" for the default entry
+func (c choiceList) Value() string {
+	return c.values[c.chosen]
+}
+
+func (c choiceList) len() int {
+	return len(c.values)
+}
+
+func (c *choiceList) choose() {
+	c.chosen = c.cursor
+}
+
+// render writes the section header and one line per entry to s
+func (c choiceList) render(s *strings.Builder, header string, focused bool) {
+	headerStyle := HeaderStyle
+	if focused {
+		headerStyle = headerStyle.Foreground(SelectedItemStyle.GetForeground())
+	}
+	s.WriteString(headerStyle.Render(header) + "\n")
+
+	for i, label := range c.labels {
+		cursor := " "
+		if focused && c.cursor == i {
+			cursor = CursorStyle.Render("❯")
+		}
+
+		radio := CheckboxUnselectedStyle.Render("○")
+		if c.chosen == i {
+			radio = CheckboxSelectedStyle.Render("◉")
+		}
+
+		item := UnselectedItemStyle.Render(label)
+		if focused && c.cursor == i {
+			item = SelectedItemStyle.Render(label)
+		}
+		if c.details[i] != "" {
+			item += LocationStyle.Render(" (" + c.details[i] + ")")
+		}
+
+		s.WriteString(fmt.Sprintf(" %s %s %s\n", cursor, radio, item))
+	}
+}
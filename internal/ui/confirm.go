@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"strings"
+
+	"cc-launcher/internal/guard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// guardIcons mark guard results by level
+var guardIcons = map[guard.Level]string{
+	guard.Pass:    "✅",
+	guard.Warn:    "⚠️",
+	guard.Confirm: "🔒",
+	guard.Refuse:  "⛔",
+}
+
+// RenderGuardResult formats a single yolo guard result as one line
+func RenderGuardResult(r guard.Result) string {
+	style := UnselectedItemStyle
+	switch r.Level {
+	case guard.Pass:
+		style = style.Foreground(MutedColor)
+	case guard.Warn, guard.Confirm:
+		style = style.Foreground(AccentColor)
+	case guard.Refuse:
+		style = style.Foreground(lipgloss.Color("#E74C3C"))
+	}
+	return guardIcons[r.Level] + " " + style.Render(r.Message)
+}
+
+// needsTyping reports whether launching requires typing guard.ConfirmWord
+func (m Model) needsTyping() bool {
+	return guard.Worst(m.guardResults) == guard.Confirm
+}
+
+// updateConfirm handles keys on the pre-launch confirmation screen
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.Quitted = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		m.confirming = false
+		return m, nil
+	case tea.KeyEnter:
+		if m.needsTyping() && m.typed != guard.ConfirmWord {
+			return m, nil
+		}
+		m.confirming = false
+		m.confirmed = true
+		return m.launch()
+	case tea.KeyBackspace:
+		if len(m.typed) > 0 {
+			m.typed = m.typed[:len(m.typed)-1]
+		}
+		return m, nil
+	case tea.KeyRunes:
+		if m.needsTyping() {
+			m.typed += string(msg.Runes)
+			return m, nil
+		}
+		if msg.String() == "n" {
+			m.confirming = false
+		}
+	}
+	return m, nil
+}
+
+// confirmView renders the pre-launch confirmation screen
+func (m Model) confirmView() string {
+	var s strings.Builder
+
+	title := CreateGradientText("⚡ Claude Code Launcher", PurpleGradientStart, PurpleGradientEnd)
+	s.WriteString(title + "\n\n")
+	s.WriteString(HeaderStyle.Render("⚠️ Skip permission checks?") + "\n")
+	for _, r := range m.guardResults {
+		s.WriteString("  " + RenderGuardResult(r) + "\n")
+	}
+
+	help := "💡 Controls: enter launch • esc back"
+	if m.needsTyping() {
+		s.WriteString("\n" + SelectedItemStyle.Render("Type \""+guard.ConfirmWord+"\" to confirm: ") + m.typed + CursorStyle.Render("█") + "\n")
+		help = "💡 Controls: enter confirm • esc back"
+	}
+	s.WriteString("\n" + HelpStyle.Render(help) + "\n")
+	return s.String()
+}
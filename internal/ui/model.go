@@ -1,12 +1,35 @@
 package ui
 
 import (
+	"context"
 	"fmt"
-	"path/filepath"
+	"os"
+	"sort"
 	"strings"
 
+	"cc-launcher/internal/claudecli"
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/git"
+	"cc-launcher/internal/guard"
+	"cc-launcher/internal/preflight"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
+)
+
+// section identifies a focusable part of the TUI
+type section int
+
+const (
+	sectionMCP section = iota
+	sectionPrompts
+	sectionFlags
+	sectionTarget
+	sectionAccount
+	sectionProvider
+	sectionModel
+	sectionSettings
+	sectionPermissions
+	sectionAddDirs
+	sectionWorktree
 )
 
 type Model struct {
@@ -16,40 +39,78 @@ type Model struct {
 	MCPFiles    []string
 	MultiSelect bool
 	Quitted     bool
-	Happy       bool
+	DryRun      bool
+	// Prompt fragments appended to the system prompt; promptSelected is
+	// keyed by index into prompts
+	prompts        []config.PromptFragment
+	promptSelected map[int]struct{}
+	promptCursor   int
 	// Flag states
-	HappyFlag    bool
 	ContinueFlag bool
 	ResumeFlag   bool
 	YoloFlag     bool
-	ZaiFlag      bool
 	// UI state
-	ShowingMCPSelection bool
-	FlagCursor          int
-	// z.ai availability
-	ZaiAvailable bool
-}
-
-func NewModel(mcpFiles []string, happy bool) Model {
-	return NewModelWithDefaults(mcpFiles, happy, false, false, false, false, false, false)
+	focus      section
+	FlagCursor int
+	// Launch target picker; sandboxed and tmux are keyed by choice value
+	targets   choiceList
+	sandboxed map[string]bool
+	tmux      map[string]bool
+	// launchError explains why enter did not launch
+	launchError string
+	// Pre-launch confirmation of the yolo guards; confirmed is kept until
+	// the selection changes
+	confirming   bool
+	confirmed    bool
+	guardResults []guard.Result
+	typed        string
+	// Permission mode and comma-separated tool patterns
+	permissionMode   string
+	allowedTools     string
+	disallowedTools  string
+	permissionCursor int
+	// extraArgs are passed to Claude Code verbatim
+	extraArgs []string
+	// Additional directories and the field for one more
+	addDirs      []addDir
+	newAddDir    string
+	addDirCursor int
+	// Branch to launch in a git worktree for, offered inside repositories
+	worktreeEnabled bool
+	worktree        string
+	worktreeCursor  int
+	// Account picker, shown when accounts are configured
+	accounts choiceList
+	// Settings variant picker, shown when variants exist; invalid ones
+	// cannot be chosen
+	settingsVariants choiceList
+	settingsInvalid  []bool
+	// Provider and model pickers; the model list depends on the provider
+	settings  config.Settings
+	available []config.Provider
+	providers choiceList
+	models    choiceList
+	// Installed claude binary, nil when unknown
+	cli *claudecli.Info
+	// Provider pre-flight check, nil when disabled
+	checker     *preflight.Checker
+	checking    bool
+	checkResult *preflight.Result
 }
 
-func NewModelWithDefaults(mcpFiles []string, happy bool, yoloFlag bool, continueFlag bool, resumeFlag bool, blankFlag bool, zaiFlag bool, zaiAvailable bool) Model {
-	choices := []string{"No mcp servers"}
+// flagOptions are the claude options enabled by the rows of the flags section
+var flagOptions = []string{"--continue", "--resume", "--dangerously-skip-permissions"}
 
-	for _, file := range mcpFiles {
-		baseName := strings.TrimSuffix(filepath.Base(file), ".json")
+// checkResultMsg delivers the outcome of a provider pre-flight check
+type checkResultMsg preflight.Result
 
-		// Determine if file is from local or global directory
-		var location string
-		if strings.HasPrefix(file, ".claude/mcp/") {
-			location = "local"
-		} else {
-			location = "global"
-		}
+func NewModelWithDefaults(mcpConfigs []config.MCPConfig, yoloFlag bool, continueFlag bool, resumeFlag bool, blankFlag bool) Model {
+	choices := []string{"No mcp servers"}
 
-		choices = append(choices, fmt.Sprintf("%s (%s)", baseName, location))
+	for _, cfg := range mcpConfigs {
+		choices = append(choices, cfg.Label())
 	}
+	mcpFiles := config.MCPPaths(mcpConfigs)
 
 	selected := make(map[int]struct{})
 	if blankFlag {
@@ -65,92 +126,453 @@ func NewModelWithDefaults(mcpFiles []string, happy bool, yoloFlag bool, continue
 		Selected:    selected,
 		MCPFiles:    mcpFiles,
 		MultiSelect: len(mcpFiles) > 1,
-		Happy:       happy,
 		// Initialize flags with command line defaults
-		HappyFlag:    happy, // Use the happy parameter passed from command line
 		ContinueFlag: continueFlag,
 		ResumeFlag:   resumeFlag,
 		YoloFlag:     yoloFlag,
-		ZaiFlag:      zaiFlag,
 		// Start with showing MCP selection
-		ShowingMCPSelection: true,
-		FlagCursor:          0,
-		// z.ai availability
-		ZaiAvailable: zaiAvailable,
+		focus:            sectionMCP,
+		FlagCursor:       0,
+		targets:          newChoiceList(config.DefaultTarget, nil, ""),
+		accounts:         newChoiceList("Default", nil, ""),
+		settingsVariants: newChoiceList("Default", nil, ""),
+		settingsInvalid:  []bool{false},
+		providers:        newChoiceList("Anthropic", nil, ""),
+		models:           newChoiceList("Default", config.DefaultModels, ""),
+	}
+}
+
+// WithProviders configures the provider and model pickers. available lists
+// the providers whose credentials resolve; provider and model are
+// pre-selected when offered.
+func (m Model) WithProviders(settings config.Settings, available []config.Provider, provider string, model string) Model {
+	m.settings = settings
+	m.available = available
+
+	names := make([]string, 0, len(available))
+	for _, p := range available {
+		names = append(names, p.Name)
+	}
+	m.providers = newChoiceList("Anthropic", names, provider)
+	for i, p := range available {
+		m.providers.details[i+1] = p.BaseURL
+	}
+
+	m.models = newChoiceList("Default", settings.ModelsFor(m.Provider()), model)
+	return m
+}
+
+// WithTargets configures the launch target picker and pre-selects target.
+// The default entry stands for config.DefaultTarget.
+func (m Model) WithTargets(settings config.Settings, target string) Model {
+	var names, details []string
+	defaultDetail := ""
+	m.settings = settings
+	m.sandboxed = make(map[string]bool)
+	m.tmux = make(map[string]bool)
+	for _, t := range settings.LaunchTargets() {
+		if t.Name == config.DefaultTarget {
+			defaultDetail = t.Command()
+			m.sandboxed[""] = t.Sandboxed()
+			m.tmux[""] = t.Tmux != nil
+			continue
+		}
+		m.sandboxed[t.Name] = t.Sandboxed()
+		m.tmux[t.Name] = t.Tmux != nil
+		names = append(names, t.Name)
+		details = append(details, t.Command())
+	}
+	m.targets = newChoiceList(config.DefaultTarget, names, target)
+	m.targets.details[0] = defaultDetail
+	copy(m.targets.details[1:], details)
+	return m
+}
+
+// WithAccounts configures the account picker and pre-selects account when
+// it is configured
+func (m Model) WithAccounts(settings config.Settings, account string) Model {
+	m.settings = settings
+	m.accounts = newChoiceList("Default", settings.AccountNames(), account)
+	for i, name := range settings.AccountNames() {
+		m.accounts.details[i+1] = settings.Accounts[name].ConfigDir
+	}
+	return m
+}
+
+// WithWorktree offers launching in a git worktree and pre-fills branch
+func (m Model) WithWorktree(branch string) Model {
+	m.worktreeEnabled = true
+	m.worktree = branch
+	return m
+}
+
+// Worktree returns the branch to launch in a worktree for, or "" to launch
+// in the current directory
+func (m Model) Worktree() string {
+	return strings.TrimSpace(m.worktree)
+}
+
+// guardDir returns the directory the yolo guards check: the worktree if
+// one is chosen, otherwise the current directory
+func (m Model) guardDir() string {
+	dir, _ := os.Getwd()
+	if m.Worktree() == "" {
+		return dir
+	}
+	root, err := git.Root(dir)
+	if err != nil {
+		return dir
+	}
+	return m.settings.Worktrees.Path(root, m.Worktree())
+}
+
+// WithCLI disables the options the installed claude binary does not support
+// and clears them if they were requested on the command line
+func (m Model) WithCLI(info *claudecli.Info) Model {
+	m.cli = info
+	if m.flagDisabled(0) {
+		m.ContinueFlag = false
+	}
+	if m.flagDisabled(1) {
+		m.ResumeFlag = false
+	}
+	if m.flagDisabled(2) {
+		m.YoloFlag = false
+	}
+	return m
+}
+
+// flagDisabled reports whether the claude option behind row i of the flags
+// section is unsupported by the installed binary
+func (m Model) flagDisabled(i int) bool {
+	return m.cli != nil && i < len(flagOptions) && !m.cli.Supports(flagOptions[i])
+}
+
+// modelDisabled reports whether the installed binary lacks --model
+func (m Model) modelDisabled() bool {
+	return m.cli != nil && !m.cli.Supports("--model")
+}
+
+// WithPreflight enables the provider check run when launching with a
+// provider selected
+func (m Model) WithPreflight(checker *preflight.Checker) Model {
+	m.checker = checker
+	return m
+}
+
+// checkProvider runs the pre-flight check for p in the background
+func (m Model) checkProvider(p config.Provider) tea.Cmd {
+	checker := *m.checker
+	return func() tea.Msg {
+		return checkResultMsg(checker.Check(context.Background(), p))
+	}
+}
+
+// WithSettingsVariants offers the Claude Code settings variants and
+// pre-selects the one at path
+func (m Model) WithSettingsVariants(variants []config.SettingsVariant, path string) Model {
+	var paths []string
+	for _, v := range variants {
+		paths = append(paths, v.Path)
+	}
+	m.settingsVariants = newChoiceList("Default", paths, path)
+	m.settingsInvalid = []bool{false}
+	m.settingsVariants.details[0] = "settings.json"
+	for i, v := range variants {
+		m.settingsVariants.labels[i+1] = v.Label()
+		m.settingsVariants.details[i+1] = v.Error
+		m.settingsInvalid = append(m.settingsInvalid, v.Error != "")
+	}
+	return m
+}
+
+// SettingsFile returns the path of the chosen settings variant, or "" to
+// use Claude Code's usual settings
+func (m Model) SettingsFile() string {
+	if m.settingsDisabled() {
+		return ""
+	}
+	return m.settingsVariants.Value()
+}
+
+// settingsDisabled reports whether the installed binary lacks --settings
+func (m Model) settingsDisabled() bool {
+	return m.cli != nil && !m.cli.Supports("--settings")
+}
+
+// Provider returns the selected provider, or the zero Provider for Anthropic
+func (m Model) Provider() config.Provider {
+	p, _ := config.FindProvider(m.available, m.providers.Value())
+	return p
+}
+
+// Target returns the name of the selected launch target, or "" for
+// config.DefaultTarget
+func (m Model) Target() string {
+	return m.targets.Value()
+}
+
+// Account returns the selected account, or the zero Account to keep the
+// inherited CLAUDE_CONFIG_DIR
+func (m Model) Account() config.Account {
+	a, _ := m.settings.FindAccount(m.accounts.Value())
+	return a
+}
+
+// SelectedModel returns the chosen model, or "" to use Claude Code's default
+func (m Model) SelectedModel() string {
+	if m.modelDisabled() {
+		return ""
+	}
+	return m.models.Value()
+}
+
+// refreshModels rebuilds the model list after the provider changed, keeping
+// the current choice when the new provider offers it
+func (m *Model) refreshModels() {
+	m.models = newChoiceList("Default", m.settings.ModelsFor(m.Provider()), m.models.Value())
+}
+
+// sections returns the focusable sections in display order
+func (m Model) sections() []section {
+	sections := []section{sectionMCP}
+	if len(m.prompts) > 0 && !m.promptsDisabled() {
+		sections = append(sections, sectionPrompts)
+	}
+	sections = append(sections, sectionFlags, sectionTarget)
+	// Only offer account and provider choices when there is something to choose
+	if m.accounts.len() > 1 {
+		sections = append(sections, sectionAccount)
+	}
+	if len(m.available) > 0 {
+		sections = append(sections, sectionProvider)
+	}
+	if !m.modelDisabled() {
+		sections = append(sections, sectionModel)
+	}
+	if m.settingsVariants.len() > 1 && !m.settingsDisabled() {
+		sections = append(sections, sectionSettings)
+	}
+	if !m.permissionsDisabled() {
+		sections = append(sections, sectionPermissions)
+	}
+	if !m.addDirsDisabled() {
+		sections = append(sections, sectionAddDirs)
+	}
+	if m.worktreeEnabled {
+		sections = append(sections, sectionWorktree)
+	}
+	return sections
+}
+
+// sectionLen returns the number of items in a section
+func (m Model) sectionLen(s section) int {
+	switch s {
+	case sectionMCP:
+		return len(m.Choices)
+	case sectionFlags:
+		return m.getMaxFlagCursor() + 1
+	case sectionTarget:
+		return m.targets.len()
+	case sectionAccount:
+		return m.accounts.len()
+	case sectionProvider:
+		return m.providers.len()
+	case sectionModel:
+		return m.models.len()
+	case sectionPrompts:
+		return len(m.prompts)
+	case sectionSettings:
+		return m.settingsVariants.len()
+	case sectionPermissions:
+		return permissionRows
+	case sectionAddDirs:
+		return len(m.addDirs) + 1
+	case sectionWorktree:
+		return 1
+	}
+	return 0
+}
+
+// cursor returns a pointer to the cursor of a section
+func (m *Model) cursor(s section) *int {
+	switch s {
+	case sectionFlags:
+		return &m.FlagCursor
+	case sectionTarget:
+		return &m.targets.cursor
+	case sectionAccount:
+		return &m.accounts.cursor
+	case sectionProvider:
+		return &m.providers.cursor
+	case sectionModel:
+		return &m.models.cursor
+	case sectionPrompts:
+		return &m.promptCursor
+	case sectionSettings:
+		return &m.settingsVariants.cursor
+	case sectionPermissions:
+		return &m.permissionCursor
+	case sectionAddDirs:
+		return &m.addDirCursor
+	case sectionWorktree:
+		return &m.worktreeCursor
+	}
+	return &m.Cursor
+}
+
+// moveFocus focuses the section offset positions away, wrapping around, and
+// places its cursor on the first or last item
+func (m *Model) moveFocus(offset int, last bool) {
+	sections := m.sections()
+	i := 0
+	for j, s := range sections {
+		if s == m.focus {
+			i = j
+		}
+	}
+	m.focus = sections[(i+offset+len(sections))%len(sections)]
+	if last {
+		*m.cursor(m.focus) = m.sectionLen(m.focus) - 1
+	} else {
+		*m.cursor(m.focus) = 0
 	}
 }
 
 // getMaxFlagCursor returns the maximum flag cursor index based on available flags
 func (m Model) getMaxFlagCursor() int {
-	// Base flags: happy, continue, resume, yolo (0-3)
-	maxCursor := 3
-	// Add z.ai if available
-	if m.ZaiAvailable {
-		maxCursor++
+	// Base flags: continue, resume, yolo (0-2)
+	return 2
+}
+
+// SelectedMCPFiles returns the selected MCP configuration files in the order
+// they are listed, or nil when "No mcp servers" is selected
+func (m Model) SelectedMCPFiles() []string {
+	if _, noMcpSelected := m.Selected[0]; noMcpSelected {
+		return nil
+	}
+
+	var indices []int
+	for i := range m.Selected {
+		if i > 0 && i-1 < len(m.MCPFiles) {
+			indices = append(indices, i)
+		}
 	}
-	return maxCursor
+	sort.Ints(indices)
+
+	files := make([]string, 0, len(indices))
+	for _, i := range indices {
+		files = append(files, m.MCPFiles[i-1])
+	}
+	return files
 }
 
 func (m Model) Init() tea.Cmd {
 	return nil
 }
 
+// launch ends the TUI, checking the selected provider first; after a
+// failed check a second enter launches anyway
+func (m Model) launch() (tea.Model, tea.Cmd) {
+	provider := m.Provider()
+	if m.checker != nil && provider.Name != "" && m.checkResult == nil {
+		m.checking = true
+		return m, m.checkProvider(provider)
+	}
+	return m, tea.Quit
+}
+
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case checkResultMsg:
+		m.checking = false
+		result := preflight.Result(msg)
+		if result.OK() {
+			return m, tea.Quit
+		}
+		m.checkResult = &result
+
 	case tea.KeyMsg:
+		// Only allow quitting while a pre-flight check is in progress
+		if m.checking && msg.String() != "ctrl+c" && msg.String() != "q" {
+			return m, nil
+		}
+		if m.confirming {
+			return m.updateConfirm(msg)
+		}
+		m.launchError = ""
+		if msg.String() != "enter" {
+			m.confirmed = false
+		}
+		// Text fields take the typed keys instead of the shortcuts
+		if m.focus == sectionWorktree && editText(&m.worktree, msg) {
+			return m, nil
+		}
+		if m.focus == sectionPermissions && m.updatePermissions(msg) {
+			return m, nil
+		}
+		if m.focus == sectionAddDirs && m.updateAddDirs(msg) {
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.Quitted = true
 			return m, tea.Quit
 
 		case "tab":
-			// Toggle between flag selection and MCP selection
-			m.ShowingMCPSelection = !m.ShowingMCPSelection
-			if m.ShowingMCPSelection {
-				m.Cursor = 0
-			} else {
-				m.FlagCursor = 0
-			}
+			// Cycle through the sections
+			m.moveFocus(1, false)
 
 		case "up", "k":
-			if m.ShowingMCPSelection {
-				if m.Cursor > 0 {
-					m.Cursor--
-				} else {
-					// At first item in MCP section, move to last item in config section
-					m.ShowingMCPSelection = false
-					m.FlagCursor = m.getMaxFlagCursor()
-				}
+			if cursor := m.cursor(m.focus); *cursor > 0 {
+				*cursor--
 			} else {
-				if m.FlagCursor > 0 {
-					m.FlagCursor--
-				} else {
-					// At first item in config section, move to last item in MCP section
-					m.ShowingMCPSelection = true
-					m.Cursor = len(m.Choices) - 1
-				}
+				// At first item in a section, move to last item in the previous one
+				m.moveFocus(-1, true)
 			}
 
 		case "down", "j":
-			if m.ShowingMCPSelection {
-				if m.Cursor < len(m.Choices)-1 {
-					m.Cursor++
-				} else {
-					// At last item in MCP section, move to first item in config section
-					m.ShowingMCPSelection = false
-					m.FlagCursor = 0
-				}
+			if cursor := m.cursor(m.focus); *cursor < m.sectionLen(m.focus)-1 {
+				*cursor++
 			} else {
-				if m.FlagCursor < m.getMaxFlagCursor() {
-					m.FlagCursor++
-				} else {
-					// At last item in config section, move to first item in MCP section
-					m.ShowingMCPSelection = true
-					m.Cursor = 0
-				}
+				// At last item in a section, move to first item in the next one
+				m.moveFocus(1, false)
 			}
 
 		case "enter":
+			if _, err := m.typedAddDir(); err != nil {
+				m.launchError = err.Error()
+				return m, nil
+			}
+			if m.tmux[m.targets.Value()] {
+				if names := m.paneChoices(); len(names) > 0 {
+					m.launchError = "The tmux panes choose their own options; unset " + strings.Join(names, ", ")
+					return m, nil
+				}
+				return m.launch()
+			}
+			if !m.skipsPermissions() || m.confirmed {
+				return m.launch()
+			}
+			if m.settings.Yolo.RequireSandbox && !m.sandboxed[m.targets.Value()] {
+				m.launchError = "Skipping permission checks requires a sandboxed launch target"
+				return m, nil
+			}
+			m.guardResults = guard.Check(m.settings.Yolo, m.guardDir(), m.SelectedMCPFiles())
+			if refusal := guard.Refusal(m.guardResults); refusal != "" {
+				m.launchError = refusal
+				return m, nil
+			}
+			if guard.Worst(m.guardResults) == guard.Pass && !m.settings.Yolo.AlwaysConfirm {
+				return m.launch()
+			}
+			m.confirming = true
+			m.typed = ""
+
+		case "d":
+			// Print the resolved command instead of launching
+			m.DryRun = true
 			return m, tea.Quit
 
 		// Number key shortcuts for MCP server selection
@@ -183,30 +605,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		// Letter key shortcuts for flag toggles
-		case "h":
-			m.HappyFlag = !m.HappyFlag
+		// Letter key shortcuts for flag toggles; options the installed
+		// claude does not support stay off
 		case "c":
+			if m.flagDisabled(0) {
+				break
+			}
 			m.ContinueFlag = !m.ContinueFlag
 			// If both continue and resume are selected, resume takes priority
 			if m.ContinueFlag && m.ResumeFlag {
 				m.ResumeFlag = false
 			}
 		case "r":
+			if m.flagDisabled(1) {
+				break
+			}
 			m.ResumeFlag = !m.ResumeFlag
 			// If both continue and resume are selected, resume takes priority
 			if m.ResumeFlag && m.ContinueFlag {
 				m.ContinueFlag = false
 			}
 		case "y":
-			m.YoloFlag = !m.YoloFlag
-		case "z":
-			if m.ZaiAvailable {
-				m.ZaiFlag = !m.ZaiFlag
+			if m.flagDisabled(2) {
+				break
 			}
-
+			m.YoloFlag = !m.YoloFlag
 		case " ":
-			if m.ShowingMCPSelection {
+			switch m.focus {
+			case sectionTarget:
+				m.targets.choose()
+			case sectionAccount:
+				m.accounts.choose()
+			case sectionProvider:
+				m.providers.choose()
+				m.refreshModels()
+				m.checkResult = nil
+			case sectionModel:
+				m.models.choose()
+			case sectionSettings:
+				if !m.settingsInvalid[m.settingsVariants.cursor] {
+					m.settingsVariants.choose()
+				}
+			case sectionPermissions:
+				if m.permissionCursor == permissionModeRow {
+					m.cyclePermissionMode()
+				}
+			case sectionAddDirs:
+				m.toggleAddDir()
+			case sectionPrompts:
+				m.togglePrompt()
+			case sectionMCP:
 				// Handle MCP selection
 				if m.MultiSelect {
 					if m.Cursor == 0 {
@@ -230,29 +678,26 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						}
 					}
 				}
-			} else {
+			case sectionFlags:
+				if m.flagDisabled(m.FlagCursor) {
+					break
+				}
 				// Handle flag selection
 				switch m.FlagCursor {
 				case 0:
-					m.HappyFlag = !m.HappyFlag
-				case 1:
 					m.ContinueFlag = !m.ContinueFlag
 					// If both continue and resume are selected, resume takes priority
 					if m.ContinueFlag && m.ResumeFlag {
 						m.ContinueFlag = false
 					}
-				case 2:
+				case 1:
 					m.ResumeFlag = !m.ResumeFlag
 					// If both continue and resume are selected, resume takes priority
 					if m.ResumeFlag && m.ContinueFlag {
 						m.ContinueFlag = false
 					}
-				case 3:
+				case 2:
 					m.YoloFlag = !m.YoloFlag
-				case 4:
-					if m.ZaiAvailable {
-						m.ZaiFlag = !m.ZaiFlag
-					}
 				}
 			}
 		}
@@ -262,22 +707,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	if m.confirming {
+		return m.confirmView()
+	}
+
 	var s strings.Builder
 
 	// Title with gradient
 	title := CreateGradientText("⚡ Claude Code Launcher", PurpleGradientStart, PurpleGradientEnd)
-	s.WriteString(title + "\n")
-
-	// Show confirmation if happy flag is set
-	if m.Happy {
-		happyStyle := lipgloss.NewStyle().Foreground(MutedColor)
-		s.WriteString(happyStyle.Render("🦦 Happy mode enabled") + "\n")
-	}
-	s.WriteString("\n")
+	s.WriteString(title + "\n\n")
 
 	// MCP section header
 	mcpHeaderStyle := HeaderStyle
-	if m.ShowingMCPSelection {
+	if m.focus == sectionMCP {
 		mcpHeaderStyle = mcpHeaderStyle.Foreground(SelectedItemStyle.GetForeground())
 	}
 	mcpHeader := mcpHeaderStyle.Render("🚀 Choose your MCP configuration:")
@@ -288,7 +730,7 @@ func (m Model) View() string {
 		var cursor, checkbox, item string
 
 		// Cursor (only show when in MCP selection mode)
-		if m.ShowingMCPSelection && m.Cursor == i {
+		if m.focus == sectionMCP && m.Cursor == i {
 			cursor = CursorStyle.Render("❯")
 		} else {
 			cursor = " "
@@ -302,7 +744,7 @@ func (m Model) View() string {
 		}
 
 		// Item styling with number shortcut
-		if m.ShowingMCPSelection && m.Cursor == i {
+		if m.focus == sectionMCP && m.Cursor == i {
 			// Special handling for different choice types
 			if i == 0 {
 				// "No mcp servers" option
@@ -347,9 +789,15 @@ func (m Model) View() string {
 
 	s.WriteString("\n")
 
+	// Prompt fragments section
+	if len(m.prompts) > 0 {
+		m.renderPrompts(&s)
+		s.WriteString("\n")
+	}
+
 	// Flags section
 	flagHeaderStyle := HeaderStyle
-	if !m.ShowingMCPSelection {
+	if m.focus == sectionFlags {
 		flagHeaderStyle = flagHeaderStyle.Foreground(SelectedItemStyle.GetForeground())
 	}
 	flagHeader := flagHeaderStyle.Render("⚙️ Configuration Flags:")
@@ -362,26 +810,18 @@ func (m Model) View() string {
 		value    bool
 		shortcut string
 	}
-	
+
 	flagChoices := []flagChoice{
-		{"happy", "🦦 Use happy [h]", m.HappyFlag, "h"},
 		{"continue", "🔄 Continue previous session [c]", m.ContinueFlag, "c"},
 		{"resume", "📂 Resume previous session [r]", m.ResumeFlag, "r"},
 		{"yolo", "⚠️ Skip permissions check [y]", m.YoloFlag, "y"},
 	}
-	
-	// Add z.ai flag if available
-	if m.ZaiAvailable {
-		flagChoices = append(flagChoices, flagChoice{
-			"zai", "⚡ Use z.ai coding plan [z]", m.ZaiFlag, "z",
-		})
-	}
 
 	for i, flag := range flagChoices {
 		var cursor, checkbox, item string
 
 		// Cursor (only show when in flag selection mode)
-		if !m.ShowingMCPSelection && m.FlagCursor == i {
+		if m.focus == sectionFlags && m.FlagCursor == i {
 			cursor = CursorStyle.Render("❯")
 		} else {
 			cursor = " "
@@ -395,21 +835,99 @@ func (m Model) View() string {
 		}
 
 		// Item styling
-		if !m.ShowingMCPSelection && m.FlagCursor == i {
+		if m.flagDisabled(i) {
+			item = UnselectedItemStyle.Foreground(MutedColor).Render(flag.label) + LocationStyle.Render("("+m.cli.Explain(flagOptions[i])+")")
+		} else if m.focus == sectionFlags && m.FlagCursor == i {
 			item = SelectedItemStyle.Render(flag.label)
 		} else {
 			item = UnselectedItemStyle.Render(flag.label)
 		}
 
+		// Make it obvious where skipped permission checks would apply
+		if flag.name == "yolo" && flag.value {
+			if m.sandboxed[m.targets.Value()] {
+				item += SandboxedBadgeStyle.Render("🛡️ sandboxed")
+			} else if m.settings.Yolo.RequireSandbox {
+				item += HostBadgeStyle.Render("⛔ sandbox required")
+			} else {
+				item += HostBadgeStyle.Render("⚠️ on host")
+			}
+		}
+
 		s.WriteString(fmt.Sprintf(" %s %s %s\n", cursor, checkbox, item))
 	}
 
+	s.WriteString("\n")
+
+	// Launch target section
+	m.targets.render(&s, "🎯 Launch target:", m.focus == sectionTarget)
+	if m.tmux[m.targets.Value()] {
+		s.WriteString(LocationStyle.Render("  Each pane runs the launcher with its own preset; the model, account and directories here are not used") + "\n")
+	}
+	s.WriteString("\n")
+
+	// Account section
+	if m.accounts.len() > 1 {
+		m.accounts.render(&s, "👤 Account:", m.focus == sectionAccount)
+		s.WriteString("\n")
+	}
+
+	// Provider section
+	if len(m.available) > 0 {
+		m.providers.render(&s, "🌐 Provider:", m.focus == sectionProvider)
+		s.WriteString("\n")
+	}
+
+	// Model section
+	if m.modelDisabled() {
+		s.WriteString(HeaderStyle.Render("🧠 Model:") + "\n")
+		s.WriteString("    " + LocationStyle.Render(m.cli.Explain("--model")) + "\n")
+	} else {
+		m.models.render(&s, "🧠 Model:", m.focus == sectionModel)
+	}
+
+	// Settings variant section
+	if m.settingsVariants.len() > 1 {
+		s.WriteString("\n")
+		if m.settingsDisabled() {
+			s.WriteString(HeaderStyle.Render("🧩 Settings file:") + "\n")
+			s.WriteString("    " + LocationStyle.Render(m.cli.Explain("--settings")) + "\n")
+		} else {
+			m.settingsVariants.render(&s, "🧩 Settings file:", m.focus == sectionSettings)
+		}
+	}
+
+	// Permissions section
+	s.WriteString("\n")
+	m.renderPermissions(&s)
+	s.WriteString("\n")
+	m.renderAddDirs(&s)
+
+	// Worktree section
+	if m.worktreeEnabled {
+		s.WriteString("\n")
+		m.renderWorktree(&s)
+	}
+
+	s.WriteString("\n")
+	m.renderPreview(&s)
+
+	// Pre-flight check status
+	if m.launchError != "" {
+		s.WriteString("\n" + RenderError(m.launchError) + "\n")
+	} else if m.checking {
+		s.WriteString("\n" + HelpStyle.Render("⏳ Checking provider "+m.Provider().Name+"...") + "\n")
+	} else if m.checkResult != nil {
+		s.WriteString("\n" + RenderError(m.checkResult.Message()) + "\n")
+		s.WriteString(HelpStyle.Render("Press enter to launch anyway or choose another provider") + "\n")
+	}
+
 	// Help text
 	helpText := "💡 Controls: "
 	if m.MultiSelect {
-		helpText += "tab switch sections • ↑/↓ navigate • space select • enter launch • q quit"
+		helpText += "tab switch sections • ↑/↓ navigate • space select • enter launch • d dry run • q quit"
 	} else {
-		helpText += "tab switch sections • ↑/↓ navigate • enter launch • q quit"
+		helpText += "tab switch sections • ↑/↓ navigate • enter launch • d dry run • q quit"
 	}
 
 	help := HelpStyle.Render(helpText)
@@ -417,3 +935,40 @@ func (m Model) View() string {
 
 	return s.String()
 }
+
+// renderWorktree writes the worktree branch field to s
+func (m Model) renderWorktree(s *strings.Builder) {
+	focused := m.focus == sectionWorktree
+	headerStyle := HeaderStyle
+	if focused {
+		headerStyle = headerStyle.Foreground(SelectedItemStyle.GetForeground())
+	}
+	s.WriteString(headerStyle.Render("🌳 Worktree branch:") + "\n")
+
+	cursor := " "
+	item := UnselectedItemStyle.Render(m.worktree)
+	if focused {
+		cursor = CursorStyle.Render("❯")
+		item = SelectedItemStyle.Render(m.worktree) + CursorStyle.Render("█")
+	}
+	if m.Worktree() == "" {
+		item += LocationStyle.Render("(type a branch to launch in its worktree)")
+	}
+	s.WriteString(fmt.Sprintf(" %s %s\n", cursor, item))
+}
+
+// editText applies a typed character or backspace to the text field s and
+// reports whether msg was such a key
+func editText(s *string, msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		*s += string(msg.Runes)
+		return true
+	case tea.KeyBackspace:
+		if runes := []rune(*s); len(runes) > 0 {
+			*s = string(runes[:len(runes)-1])
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"cc-launcher/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Rows of the permissions section
+const (
+	permissionModeRow = iota
+	allowedToolsRow
+	disallowedToolsRow
+	permissionRows
+)
+
+// WithPermissions pre-fills the permission mode and the allowed and
+// disallowed tool patterns
+func (m Model) WithPermissions(mode string, allowed, disallowed []string) Model {
+	m.permissionMode = mode
+	m.allowedTools = strings.Join(allowed, ", ")
+	m.disallowedTools = strings.Join(disallowed, ", ")
+	return m
+}
+
+// WithExtraArgs records the arguments passed to Claude Code verbatim, which
+// can skip permission checks as well
+func (m Model) WithExtraArgs(args []string) Model {
+	m.extraArgs = args
+	return m
+}
+
+// PermissionMode returns the chosen permission mode, or "" to use Claude
+// Code's default
+func (m Model) PermissionMode() string {
+	if m.permissionsDisabled() {
+		return ""
+	}
+	return m.permissionMode
+}
+
+// AllowedTools returns the tool patterns Claude Code may use without asking
+func (m Model) AllowedTools() []string {
+	if m.permissionsDisabled() {
+		return nil
+	}
+	return splitPatterns(m.allowedTools)
+}
+
+// DisallowedTools returns the tool patterns Claude Code must not use
+func (m Model) DisallowedTools() []string {
+	if m.permissionsDisabled() {
+		return nil
+	}
+	return splitPatterns(m.disallowedTools)
+}
+
+// skipsPermissions reports whether the launch runs without asking, either
+// with yolo or in the bypassPermissions mode, including when the extra
+// arguments ask for either
+func (m Model) skipsPermissions() bool {
+	return m.YoloFlag || m.PermissionMode() == config.BypassPermissions || config.SkipPermissionsFlag(m.extraArgs) != ""
+}
+
+// permissionsDisabled reports whether the installed binary lacks
+// --permission-mode
+func (m Model) permissionsDisabled() bool {
+	return m.cli != nil && !m.cli.Supports("--permission-mode")
+}
+
+// cyclePermissionMode switches to the next permission mode, starting over
+// with Claude Code's default after the last one
+func (m *Model) cyclePermissionMode() {
+	modes := append([]string{""}, config.PermissionModes...)
+	i := slices.Index(modes, m.permissionMode)
+	m.permissionMode = modes[(i+1)%len(modes)]
+}
+
+// updatePermissions lets the tool pattern rows take typed keys. It reports
+// whether msg was handled.
+func (m *Model) updatePermissions(msg tea.KeyMsg) bool {
+	switch m.permissionCursor {
+	case allowedToolsRow:
+		return editText(&m.allowedTools, msg)
+	case disallowedToolsRow:
+		return editText(&m.disallowedTools, msg)
+	}
+	return false
+}
+
+// renderPermissions writes the permissions section to s
+func (m Model) renderPermissions(s *strings.Builder) {
+	if m.permissionsDisabled() {
+		s.WriteString(HeaderStyle.Render("🔐 Permissions:") + "\n")
+		s.WriteString("    " + LocationStyle.Render(m.cli.Explain("--permission-mode")) + "\n")
+		return
+	}
+
+	focused := m.focus == sectionPermissions
+	headerStyle := HeaderStyle
+	if focused {
+		headerStyle = headerStyle.Foreground(SelectedItemStyle.GetForeground())
+	}
+	s.WriteString(headerStyle.Render("🔐 Permissions:") + "\n")
+
+	mode := m.permissionMode
+	if mode == "" {
+		mode = "Claude Code default"
+	}
+	rows := []struct {
+		label, value, hint string
+		text               bool
+	}{
+		{"Mode:      ", mode, "(space to change)", false},
+		{"Allowed:   ", m.allowedTools, "(comma-separated tool patterns, e.g. Read, Bash(git diff:*))", true},
+		{"Disallowed:", m.disallowedTools, "(comma-separated tool patterns, e.g. Bash(git push:*))", true},
+	}
+	for i, row := range rows {
+		cursor := " "
+		item := UnselectedItemStyle.Render(row.value)
+		if focused && m.permissionCursor == i {
+			cursor = CursorStyle.Render("❯")
+			item = SelectedItemStyle.Render(row.value)
+			if row.text {
+				item += CursorStyle.Render("█")
+			}
+		}
+		switch {
+		case row.text && strings.TrimSpace(row.value) == "":
+			item += LocationStyle.Render(row.hint)
+		case !row.text && focused && m.permissionCursor == i:
+			item += " " + LocationStyle.Render(row.hint)
+		}
+		if i == permissionModeRow && m.permissionMode == config.BypassPermissions {
+			item += " " + HostBadgeStyle.Render("⚠️ never asks")
+		}
+		s.WriteString(fmt.Sprintf(" %s %s %s\n", cursor, UnselectedItemStyle.Render(row.label), item))
+	}
+}
+
+// splitPatterns splits a comma-separated list of tool patterns
+func splitPatterns(list string) []string {
+	var patterns []string
+	for _, p := range strings.Split(list, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"cc-launcher/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEnterGuardsExtraArgsSkippingPermissions(t *testing.T) {
+	settings := config.Settings{Yolo: config.YoloSettings{RequireSandbox: true}}
+	tests := []struct {
+		name    string
+		args    []string
+		refused bool
+	}{
+		{name: "no extra args"},
+		{name: "harmless", args: []string{"--verbose"}},
+		{name: "skip flag", args: []string{"--dangerously-skip-permissions"}, refused: true},
+		{name: "bypass mode", args: []string{"--permission-mode", "bypassPermissions"}, refused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModelWithDefaults(nil, false, false, false, true).
+				WithTargets(settings, "").
+				WithExtraArgs(tt.args)
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			got := next.(Model)
+			if refused := strings.Contains(got.launchError, "sandboxed"); refused != tt.refused {
+				t.Errorf("refused = %v (launchError %q), want %v", refused, got.launchError, tt.refused)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/shellquote"
+)
+
+// previewArgs returns the claude options following from the current
+// selections. MCP configurations, the launch target and extra arguments
+// are left out; the dry run shows the complete command.
+func (m Model) previewArgs() []string {
+	args := []string{"claude"}
+	if m.YoloFlag {
+		args = append(args, "--dangerously-skip-permissions")
+	}
+	if m.ResumeFlag {
+		args = append(args, "--resume")
+	} else if m.ContinueFlag {
+		args = append(args, "--continue")
+	}
+	if model := m.SelectedModel(); model != "" {
+		args = append(args, "--model", model)
+	}
+	if text := m.AppendSystemPrompt(); text != "" {
+		// The text itself is shown in the prompt fragments section
+		args = append(args, "--append-system-prompt", fmt.Sprintf("<%d fragments, ≈%d tokens>", len(m.SelectedPrompts()), config.EstimateTokens(text)))
+	}
+	if file := m.SettingsFile(); file != "" {
+		args = append(args, "--settings", file)
+	}
+	if mode := m.PermissionMode(); mode != "" {
+		args = append(args, "--permission-mode", mode)
+	}
+	if tools := m.AllowedTools(); len(tools) > 0 {
+		args = append(append(args, "--allowedTools"), tools...)
+	}
+	if tools := m.DisallowedTools(); len(tools) > 0 {
+		args = append(append(args, "--disallowedTools"), tools...)
+	}
+	if dirs := m.AddDirs(); len(dirs) > 0 {
+		args = append(append(args, "--add-dir"), dirs...)
+	}
+	return args
+}
+
+// renderPreview writes the launch preview line to s
+func (m Model) renderPreview(s *strings.Builder) {
+	s.WriteString(HeaderStyle.Render("📋 Launch preview:") + "\n")
+	s.WriteString("    " + LocationStyle.Render(shellquote.Join(m.previewArgs())) + "\n")
+}
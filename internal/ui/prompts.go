@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"cc-launcher/internal/config"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// promptPreviewLines limits the preview of the combined prompt text
+const promptPreviewLines = 8
+
+var promptPreviewStyle = lipgloss.NewStyle().
+	Foreground(MutedColor).
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(MutedColor).
+	Padding(0, 1).
+	MarginLeft(4)
+
+// WithPrompts offers the prompt fragments, with those in selected (indexes
+// into fragments) chosen
+func (m Model) WithPrompts(fragments []config.PromptFragment, selected map[int]struct{}) Model {
+	m.prompts = fragments
+	m.promptSelected = make(map[int]struct{})
+	for i := range selected {
+		m.promptSelected[i] = struct{}{}
+	}
+	return m
+}
+
+// SelectedPrompts returns the chosen prompt fragments in order
+func (m Model) SelectedPrompts() []config.PromptFragment {
+	return config.SelectedPrompts(m.prompts, m.promptSelected)
+}
+
+// AppendSystemPrompt returns the text of the chosen fragments, or "" to
+// keep Claude Code's system prompt as it is
+func (m Model) AppendSystemPrompt() string {
+	if m.promptsDisabled() {
+		return ""
+	}
+	return config.JoinPrompts(m.SelectedPrompts())
+}
+
+// promptsDisabled reports whether the installed binary lacks
+// --append-system-prompt
+func (m Model) promptsDisabled() bool {
+	return m.cli != nil && !m.cli.Supports("--append-system-prompt")
+}
+
+// togglePrompt chooses or drops the fragment under the cursor unless it
+// could not be read
+func (m *Model) togglePrompt() {
+	if m.prompts[m.promptCursor].Error != "" {
+		return
+	}
+	if _, ok := m.promptSelected[m.promptCursor]; ok {
+		delete(m.promptSelected, m.promptCursor)
+	} else {
+		m.promptSelected[m.promptCursor] = struct{}{}
+	}
+}
+
+// renderPrompts writes the prompt fragments section to s, followed by a
+// preview of the combined text when fragments are chosen
+func (m Model) renderPrompts(s *strings.Builder) {
+	if m.promptsDisabled() {
+		s.WriteString(HeaderStyle.Render("🎭 Prompt fragments:") + "\n")
+		s.WriteString("    " + LocationStyle.Render(m.cli.Explain("--append-system-prompt")) + "\n")
+		return
+	}
+
+	focused := m.focus == sectionPrompts
+	headerStyle := HeaderStyle
+	if focused {
+		headerStyle = headerStyle.Foreground(SelectedItemStyle.GetForeground())
+	}
+	s.WriteString(headerStyle.Render("🎭 Prompt fragments:") + "\n")
+
+	for i, p := range m.prompts {
+		cursor := " "
+		item := UnselectedItemStyle.Render(p.Name)
+		if focused && m.promptCursor == i {
+			cursor = CursorStyle.Render("❯")
+			item = SelectedItemStyle.Render(p.Name)
+		}
+
+		checkbox := CheckboxUnselectedStyle.Render("⬡")
+		if _, ok := m.promptSelected[i]; ok {
+			checkbox = CheckboxSelectedStyle.Render("⬢")
+		}
+		if p.Error != "" {
+			checkbox = CheckboxUnselectedStyle.Render("✗")
+			item += LocationStyle.Render(" (" + p.Origin + ", " + p.Error + ")")
+		} else {
+			item += LocationStyle.Render(" (" + p.Origin + ")")
+		}
+		s.WriteString(fmt.Sprintf(" %s %s %s\n", cursor, checkbox, item))
+	}
+
+	text := m.AppendSystemPrompt()
+	if text == "" {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > promptPreviewLines {
+		lines = append(lines[:promptPreviewLines], fmt.Sprintf("… %d more lines", len(lines)-promptPreviewLines))
+	}
+	for i, line := range lines {
+		if runes := []rune(line); len(runes) > 72 {
+			lines[i] = string(runes[:71]) + "…"
+		}
+	}
+	s.WriteString("\n" + LocationStyle.Render(fmt.Sprintf("    📝 Appended to the system prompt (≈%d tokens):", config.EstimateTokens(text))) + "\n")
+	s.WriteString(promptPreviewStyle.Render(strings.Join(lines, "\n")) + "\n")
+}
@@ -100,4 +100,13 @@ var (
 			Foreground(SuccessColor).
 			Bold(true).
 			Padding(0, 1)
+
+	// Badges shown next to yolo mode
+	SandboxedBadgeStyle = lipgloss.NewStyle().
+				Foreground(SuccessColor).
+				Bold(true)
+
+	HostBadgeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#E74C3C")).
+			Bold(true)
 )
\ No newline at end of file
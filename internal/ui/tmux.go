@@ -0,0 +1,28 @@
+package ui
+
+// paneChoices names the chosen options that the panes of a tmux target
+// cannot take over, since each runs the launcher with its own preset
+func (m Model) paneChoices() []string {
+	set := []struct {
+		name string
+		ok   bool
+	}{
+		{"MCP servers", len(m.SelectedMCPFiles()) > 0},
+		{"yolo", m.YoloFlag},
+		{"resume", m.ResumeFlag},
+		{"continue", m.ContinueFlag},
+		{"provider", m.Provider().Name != ""},
+		{"prompts", len(m.SelectedPrompts()) > 0},
+		{"settings", m.SettingsFile() != ""},
+		{"permission mode", m.PermissionMode() != ""},
+		{"allowed tools", len(m.AllowedTools()) > 0},
+		{"disallowed tools", len(m.DisallowedTools()) > 0},
+	}
+	var names []string
+	for _, s := range set {
+		if s.ok {
+			names = append(names, s.name)
+		}
+	}
+	return names
+}
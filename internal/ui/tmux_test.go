@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"cc-launcher/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEnterWithTmuxTargetRefusesPaneChoices(t *testing.T) {
+	settings := config.Settings{Targets: map[string]config.Target{"team": {Tmux: &config.Tmux{Panes: []config.TmuxPane{{Name: "a"}}}}}}
+	tests := []struct {
+		name    string
+		yolo    bool
+		mode    string
+		refused bool
+	}{
+		{name: "nothing chosen"},
+		{name: "yolo", yolo: true, refused: true},
+		{name: "permission mode", mode: "plan", refused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewModelWithDefaults(nil, tt.yolo, false, false, true).
+				WithTargets(settings, "team").
+				WithPermissions(tt.mode, nil, nil)
+			next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			got := next.(Model)
+			if refused := strings.Contains(got.launchError, "tmux"); refused != tt.refused {
+				t.Errorf("refused = %v (launchError %q), want %v", refused, got.launchError, tt.refused)
+			}
+			if !tt.refused && cmd == nil {
+				t.Error("enter did not launch")
+			}
+		})
+	}
+}
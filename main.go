@@ -1,113 +1,337 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"log"
 	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
+	"cc-launcher/internal/claudecli"
 	"cc-launcher/internal/config"
+	"cc-launcher/internal/git"
+	"cc-launcher/internal/hooks"
 	"cc-launcher/internal/launcher"
+	"cc-launcher/internal/preflight"
+	"cc-launcher/internal/secrets"
 	"cc-launcher/internal/ui"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	// Subcommands (list, inspect, ...) are dispatched before flag parsing
+	if code, ok := runSubcommand(os.Args[1:]); ok {
+		os.Exit(code)
+	}
+
 	// Parse command line flags
-	var debugFlag bool
-	var localFlag bool
-	var yoloFlag bool
-	var happyFlag bool
-	var resumeFlag bool
-	var continueFlag bool
-	var blankFlag bool
-	var configFlag bool
-	var zaiFlag bool
-	flag.BoolVar(&debugFlag, "debug", false, "Enable debug logging")
-	flag.BoolVar(&localFlag, "local", false, "Only check for local MCP configurations, skip global ones")
-	flag.BoolVar(&yoloFlag, "yolo", false, "Launch Claude Code with --dangerously-skip-permissions")
-	flag.BoolVar(&happyFlag, "happy", false, "Use happy instead of claude command")
-	flag.BoolVar(&resumeFlag, "r", false, "Launch Claude Code with --resume flag (-r, --resume)")
-	flag.BoolVar(&resumeFlag, "resume", false, "Launch Claude Code with --resume flag (-r, --resume)")
-	flag.BoolVar(&continueFlag, "continue", false, "Launch Claude Code with --continue flag (--continue)")
-	flag.BoolVar(&configFlag, "c", false, "Always show TUI config interface (overrides other flags) (-c, --config)")
-	flag.BoolVar(&configFlag, "config", false, "Always show TUI config interface (overrides other flags) (-c, --config)")
-	flag.BoolVar(&blankFlag, "b", false, "Launch Claude Code without MCP servers (skip TUI) (-b, --blank)")
-	flag.BoolVar(&blankFlag, "blank", false, "Launch Claude Code without MCP servers (skip TUI) (-b, --blank)")
-	flag.BoolVar(&zaiFlag, "zai", false, "Use z.ai coding plan (requires Z_AI_API_KEY environment variable)")
-	
+	flags := registerFlags(flag.CommandLine)
+
 	// Custom usage function to show double dashes for all flags except -r, -c, and -b
 	flag.Usage = func() {
 		fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s [flags] [-- claude args...]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s list [--json] [--local]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s inspect [--json] [--local] <name>\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s completion bash|zsh|fish\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s run [--dry-run] [--executor name] plan.json\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s secrets set|get|rm <name> | secrets list\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s checkpoints list | checkpoints diff|restore [name]\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s tmux attach [session]\n\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "Flags:\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --account name\n        Use the named Claude account from config.json (sets CLAUDE_CONFIG_DIR)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --add-dir dir[,dir...]\n        Give Claude Code access to an additional directory (repeatable)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --allowed-tools pattern[,pattern...]\n        Let Claude Code use matching tools without asking (repeatable)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -b, --blank\n        Launch Claude Code without MCP servers (skip TUI)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -c, --config\n        Always show TUI config interface (overrides other flags)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  --continue\n        Launch Claude Code with --continue flag\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  --debug\n        Enable debug logging\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "  --happy\n        Use happy instead of claude command\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --disallowed-tools pattern[,pattern...]\n        Forbid Claude Code matching tools (repeatable)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --dry-run\n        Print the resolved command and environment instead of launching\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --happy\n        Use happy instead of claude command (same as --target happy)\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  --local\n        Only check for local MCP configurations, skip global ones\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --mcp name[,name...]\n        Launch with the named MCP configurations (repeatable)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --permission-mode mode\n        Launch in a permission mode: default, plan, acceptEdits or bypass\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --preflight-timeout duration\n        Timeout for the provider check (default 5s)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --preset name\n        Launch with a preset from .claude/launcher/config.json\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --prompt name[,name...]\n        Append the named prompt fragments from .claude/prompts to the system prompt\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --provider name[,name...]\n        Use the named provider profile; a list is tried in order until one passes the check\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  -r, --resume\n        Launch Claude Code with --resume flag\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --save-plan file\n        Save the resolved launch plan to a JSON file instead of launching\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --settings name\n        Launch with the Claude Code settings variant .claude/settings.<name>.json\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --skip-preflight\n        Launch without checking the provider first\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --supervise\n        Keep the launcher running as Claude Code's parent to clean up and report afterwards\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --target name\n        Launch with the named launch target (claude, happy, npx or one from config.json)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --worktree branch\n        Launch in a git worktree for the branch, creating both if needed\n")
 		fmt.Fprintf(flag.CommandLine.Output(), "  --yolo\n        Launch Claude Code with --dangerously-skip-permissions\n")
-		fmt.Fprintf(flag.CommandLine.Output(), "  --zai\n        Use z.ai coding plan (requires Z_AI_API_KEY environment variable)\n")
+		fmt.Fprintf(flag.CommandLine.Output(), "  --zai\n        Use z.ai coding plan (same as --provider zai, requires Z_AI_API_KEY)\n")
 	}
-	
+
 	flag.Parse()
 
-	// Validate zai flag requires Z_AI_API_KEY
-	if zaiFlag {
-		zaiAPIKey := os.Getenv("Z_AI_API_KEY")
-		if zaiAPIKey == "" {
-			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: --zai flag requires Z_AI_API_KEY environment variable to be set"))
+	// Set debug mode in config package
+	config.SetDebugMode(flags.debug)
+
+	// Secrets referenced by providers and MCP files are decrypted on first use
+	unlocker := &secrets.Unlocker{}
+	config.SetSecretResolver(unlocker.Get)
+
+	// Everything after "--" is passed to Claude Code verbatim
+	passThrough, err := passThroughArgs(os.Args[1:], flag.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	settings, err := config.LoadSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error loading launcher settings: "+err.Error()))
+		os.Exit(1)
+	}
+	flags.extraArgs = settings.Args
+	flags.supervise = flags.supervise || settings.Supervise
+	flags.requireSandbox = settings.Yolo.RequireSandbox
+	flags.checkpoints = settings.Checkpoints
+
+	// Apply the preset on top of the command line flags
+	if flags.preset != "" {
+		preset, ok := settings.Presets[flags.preset]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: unknown preset "+flags.preset))
 			os.Exit(1)
 		}
+		flags.applyPreset(preset)
 	}
+	flags.extraArgs = append(flags.extraArgs, passThrough...)
 
-	// Check if Z_AI_API_KEY is available for TUI
-	zaiAvailable := os.Getenv("Z_AI_API_KEY") != ""
+	if flags.permissionMode != "" {
+		if flags.permissionMode, err = config.PermissionMode(flags.permissionMode); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			os.Exit(1)
+		}
+	}
 
-	// Set debug mode in config package
-	config.SetDebugMode(debugFlag)
+	// --happy is kept as a shorthand for --target happy
+	if flags.happy && flags.target == "" {
+		flags.target = "happy"
+	}
+	targets, err := settings.TargetChain(flags.target)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		os.Exit(1)
+	}
 
-	// Check if any flags were provided (excluding configFlag which forces TUI)
-	anyFlagProvided := debugFlag || localFlag || yoloFlag || happyFlag || resumeFlag || continueFlag || blankFlag || zaiFlag
-	
-	// If any flag is provided but config flag is NOT set, bypass TUI and launch directly with defaults
-	if anyFlagProvided && !configFlag {
-		// Apply default values: blank=true (skip TUI), all others keep their parsed values (defaulting to false)
-		err := launcher.LaunchClaudeCodeWithoutMCP(yoloFlag, happyFlag, resumeFlag, continueFlag, zaiFlag)
+	// --zai is kept as a shorthand for --provider zai
+	if flags.zai && flags.provider == "" {
+		flags.provider = "zai"
+	}
+
+	providers, err := config.LoadProviders(settings.ProjectProviderTokens)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error loading provider profiles: "+err.Error()))
+		os.Exit(1)
+	}
+
+	// Provider pre-flight check, skipped for dry runs and saved plans
+	var checker *preflight.Checker
+	if !settings.Preflight.Disabled && !flags.skipPreflight && !flags.dryRun && flags.savePlan == "" {
+		timeout, err := settings.Preflight.TimeoutDuration()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error launching Claude Code: "+err.Error()))
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
 			os.Exit(1)
 		}
-		return
+		if flags.preflightTimeout > 0 {
+			timeout = flags.preflightTimeout
+		}
+		checker = &preflight.Checker{Timeout: timeout}
 	}
 
-	// Find MCP files (command line flag takes precedence over TUI setting)
-	mcpFiles, err := config.FindMCPFiles(localFlag)
+	// Validate the requested providers exist. A comma-separated list is a
+	// failover chain: the first provider passing the check is used.
+	var provider config.Provider
+	if flags.provider != "" {
+		var chain []config.Provider
+		for _, name := range strings.Split(flags.provider, ",") {
+			p, ok := config.FindProvider(providers, strings.TrimSpace(name))
+			if !ok {
+				fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: unknown provider "+name))
+				os.Exit(1)
+			}
+			chain = append(chain, p)
+		}
+
+		if len(chain) == 1 {
+			provider = chain[0]
+			if _, err := provider.Token(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+				os.Exit(1)
+			}
+		} else {
+			var results []preflight.Result
+			provider, results, err = preflight.SelectProvider(context.Background(), checker, chain)
+			launcher.ShowFailoverResults(results)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+				os.Exit(1)
+			}
+			// The chosen provider was just checked
+			checker = nil
+		}
+		flags.provider = provider.Name
+	}
+
+	// The account comes from --account or the preset, otherwise from the
+	// first account whose paths contain the project directory
+	var account config.Account
+	if flags.account != "" {
+		var ok bool
+		account, ok = settings.FindAccount(flags.account)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: unknown account "+flags.account))
+			os.Exit(1)
+		}
+	} else if dir, err := os.Getwd(); err == nil {
+		account, _ = settings.AccountForDir(dir)
+	}
+
+	// Prompt fragments are appended to the system prompt by name
+	prompts, err := config.DiscoverPrompts(flags.local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding prompt fragments: "+err.Error()))
+		os.Exit(1)
+	}
+	promptSelected, err := selectPrompts(prompts, flags.prompts)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding MCP files: "+err.Error()))
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
 		os.Exit(1)
 	}
+	flags.appendSystemPrompt = config.JoinPrompts(config.SelectedPrompts(prompts, promptSelected))
 
-	// If config flag is set, always show TUI even with no MCP files
-	if configFlag && len(mcpFiles) == 0 {
-		// Create empty MCP files list to force TUI
-		mcpFiles = []string{}
+	// Settings variants are alternative Claude Code settings files
+	variants, err := config.DiscoverSettingsVariants(flags.local)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding settings variants: "+err.Error()))
+		os.Exit(1)
+	}
+	if flags.settings != "" {
+		variant, ok := config.FindSettingsVariant(variants, flags.settings)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: no settings variant named "+flags.settings))
+			os.Exit(1)
+		}
+		if variant.Error != "" {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: settings variant "+flags.settings+": "+variant.Error))
+			os.Exit(1)
+		}
+		flags.settingsFile = variant.Path
 	}
 
-	if len(mcpFiles) == 0 && !configFlag {
-		// Show styled no-MCP message and launch without MCP
-		launcher.ShowNoMCPMessage(happyFlag)
+	// Options the installed claude does not support are disabled
+	cli := detectCLI(flags.debug)
+
+	// Check if any flags were provided (excluding the config flag which forces TUI).
+	// Like the others, the option flags also count when a preset sets them.
+	anyFlagProvided := flags.debug || flags.local || flags.yolo || flags.target != "" || flags.resume || flags.continueSession || flags.blank || flags.provider != "" || flags.account != "" || len(passThrough) > 0 ||
+		flags.settings != "" || len(flags.addDirs) > 0 || flags.permissionMode != "" || len(flags.allowedTools) > 0 || len(flags.disallowedTools) > 0 || len(flags.prompts) > 0
+
+	// Named MCP configurations launch directly with that selection
+	var mcpConfigs []config.MCPConfig
+	var mcpSelected map[int]struct{}
+	if len(flags.mcp) > 0 && !flags.blank {
+		mcpConfigs, err = config.DiscoverMCPConfigs(flags.local)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding MCP files: "+err.Error()))
+			os.Exit(1)
+		}
+		mcpSelected, err = selectMCPConfigs(mcpConfigs, flags.mcp)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			os.Exit(1)
+		}
+
+		if !flags.config {
+			opts := flags.launchOptions(targets, provider, account, cli)
+			opts.MCPFiles = selectedMCPFiles(mcpConfigs, mcpSelected)
+			if !flags.dryRun && flags.savePlan == "" {
+				launcher.ShowLaunchMessage(flags.target)
+			}
+			launchDirect(opts, flags, settings, checker)
+			return
+		}
+	}
 
-		err := launcher.LaunchClaudeCodeWithoutMCP(yoloFlag, happyFlag, resumeFlag, continueFlag, zaiFlag)
+	// If any flag is provided but config flag is NOT set, bypass TUI and launch directly with defaults
+	if anyFlagProvided && !flags.config {
+		// Apply default values: blank=true (skip TUI), all others keep their parsed values (defaulting to false)
+		launchDirect(flags.launchOptions(targets, provider, account, cli), flags, settings, checker)
+		return
+	}
+
+	// Find MCP files (command line flag takes precedence over TUI setting)
+	if mcpConfigs == nil {
+		mcpConfigs, err = config.DiscoverMCPConfigs(flags.local)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error launching Claude Code: "+err.Error()))
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error finding MCP files: "+err.Error()))
 			os.Exit(1)
 		}
+	}
+
+	// If config flag is set, always show TUI even with no MCP files
+	if flags.config && len(mcpConfigs) == 0 {
+		// Create empty MCP files list to force TUI
+		mcpConfigs = []config.MCPConfig{}
+	}
+
+	if len(mcpConfigs) == 0 && !flags.config {
+		// Show styled no-MCP message and launch without MCP
+		if !flags.dryRun && flags.savePlan == "" {
+			launcher.ShowNoMCPMessage(flags.target)
+		}
+		launchDirect(flags.launchOptions(targets, provider, account, cli), flags, settings, checker)
 		return
 	}
 
 	// Create UI model and run Bubble Tea program
 	// Pass command line flags as defaults when config flag is used
-	m := ui.NewModelWithDefaults(mcpFiles, happyFlag, yoloFlag, continueFlag, resumeFlag, blankFlag, zaiFlag, zaiAvailable)
+	state, err := config.LoadState()
+	if err != nil && flags.debug {
+		log.Printf("Warning: %v", err)
+	}
+	// Unlock the secret store before the TUI takes over the terminal, so
+	// providers using authTokenSecret can be offered
+	if usesSecrets(providers) {
+		if err := unlocker.Unlock(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: secret store locked: "+err.Error()))
+		}
+	}
+	model := flags.model
+	if model == "" {
+		model = state.Project().Model
+	}
+	m := ui.NewModelWithDefaults(mcpConfigs, flags.yolo, flags.continueSession, flags.resume, flags.blank).
+		WithProviders(settings, config.AvailableProviders(providers), flags.provider, model).
+		WithTargets(settings, flags.target).
+		WithAccounts(settings, account.Name).
+		WithPermissions(flags.permissionMode, flags.allowedTools, flags.disallowedTools).
+		WithExtraArgs(flags.extraArgs).
+		WithAddDirs(append(append(stringList{}, settings.AddDirs...), flags.addDirs...)).
+		WithPrompts(prompts, promptSelected).
+		WithSettingsVariants(variants, flags.settingsFile).
+		WithCLI(cli).
+		WithPreflight(checker)
+	if cwd, err := os.Getwd(); err == nil {
+		if _, err := git.Root(cwd); err == nil {
+			m = m.WithWorktree(flags.worktree)
+		}
+	}
+	if mcpSelected != nil {
+		m.Selected = mcpSelected
+	}
 	p := tea.NewProgram(m)
 	finalModel, err := p.Run()
 	if err != nil {
@@ -124,18 +348,391 @@ func main() {
 			effectiveContinueFlag = false // resume takes priority
 		}
 
-		launcher.ShowLaunchMessage(finalModel.HappyFlag || happyFlag)
-		err := launcher.LaunchClaudeCode(
-			finalModel.Selected, 
-			finalModel.MCPFiles, 
-			finalModel.YoloFlag, 
-			finalModel.HappyFlag || happyFlag, 
-			effectiveResumeFlag, 
-			effectiveContinueFlag,
-			finalModel.ZaiFlag)
+		// Remember the model choice for this project
+		project := state.Project()
+		project.Model = finalModel.SelectedModel()
+		if err := state.SetProject(project); err == nil {
+			if err := state.Save(); err != nil && flags.debug {
+				log.Printf("Warning: %v", err)
+			}
+		}
+
+		targets, err := settings.TargetChain(finalModel.Target())
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error launching Claude Code: "+err.Error()))
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			os.Exit(1)
+		}
+
+		dryRun := finalModel.DryRun || flags.dryRun
+		if !dryRun && flags.savePlan == "" {
+			launcher.ShowLaunchMessage(finalModel.Target())
+		}
+		opts := launcher.Options{
+			MCPFiles:           finalModel.SelectedMCPFiles(),
+			Yolo:               finalModel.YoloFlag,
+			Targets:            targets,
+			Resume:             effectiveResumeFlag,
+			Continue:           effectiveContinueFlag,
+			Provider:           finalModel.Provider(),
+			Account:            finalModel.Account(),
+			Model:              finalModel.SelectedModel(),
+			AppendSystemPrompt: finalModel.AppendSystemPrompt(),
+			SettingsFile:       finalModel.SettingsFile(),
+			PermissionMode:     finalModel.PermissionMode(),
+			AllowedTools:       finalModel.AllowedTools(),
+			DisallowedTools:    finalModel.DisallowedTools(),
+			AddDirs:            finalModel.AddDirs(),
+			ExtraArgs:          flags.extraArgs,
+			Supervise:          flags.supervise,
+			CLI:                cli,
+			RequireSandbox:     flags.requireSandbox,
+		}
+		if tmuxTarget(targets) {
+			// The panes choose their model, account and directories
+			// themselves, and the TUI refused the other options
+			opts.Model, opts.Account, opts.AddDirs = "", config.Account{}, nil
+			opts.ExtraArgs = opts.ExtraArgs[len(settings.Args):]
+		}
+		opts.Checkpoint = flags.checkpoints.Enabled(opts.SkipsPermissions())
+		if branch := finalModel.Worktree(); branch != "" {
+			if err := enterWorktree(&opts, branch, settings.Worktrees, dryRun); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+				os.Exit(1)
+			}
+		}
+		launch(opts, dryRun, flags.savePlan, nil) // the TUI already ran the pre-flight check
+	}
+}
+
+// launch plans the launch and executes it, or prints what would be run when
+// dryRun is set, or saves the plan when savePlan names a file. When checker
+// is set and a provider is selected, the provider is checked first. It
+// exits the process unless the plan was only printed or saved.
+func launch(opts launcher.Options, dryRun bool, savePlan string, checker *preflight.Checker) {
+	plan, err := launcher.Plan(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error resolving Claude Code command: "+err.Error()))
+		os.Exit(1)
+	}
+
+	if dryRun {
+		launcher.PrintDryRun(os.Stdout, plan)
+		// Nothing will read the generated files, which may contain secrets
+		plan.RemoveGenerated()
+		return
+	}
+
+	// Saved plans hold no credentials, see LaunchPlan.Save
+	if savePlan != "" {
+		if err := plan.Save(savePlan); err != nil {
+			plan.RemoveGenerated()
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			os.Exit(1)
+		}
+		fmt.Printf("Saved launch plan to %s (run it with: cc-launcher run %s)\n", savePlan, savePlan)
+		return
+	}
+
+	if checker != nil && opts.Provider.Name != "" {
+		result := checker.Check(context.Background(), opts.Provider)
+		if !result.OK() {
+			plan.RemoveGenerated()
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+result.Message()+" (use --skip-preflight to launch anyway)"))
 			os.Exit(1)
 		}
 	}
-}
\ No newline at end of file
+
+	os.Exit(execute(plan))
+}
+
+// execute runs the pre-launch hooks and then the plan with its executor,
+// followed by the post-exit hooks. It returns the exit code for the
+// launcher, unless the executor replaced the process.
+func execute(plan launcher.LaunchPlan) int {
+	if err := hooks.Run(hooks.NewInput(hooks.PreLaunch, plan)); err != nil {
+		plan.RemoveGenerated()
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Launch aborted: "+err.Error()))
+		return 1
+	}
+
+	if plan.Checkpoint {
+		if err := recordCheckpoint(plan.Dir); err != nil {
+			plan.RemoveGenerated()
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Launch aborted: checkpoint failed: "+err.Error()))
+			return 1
+		}
+	}
+
+	// Post-exit hooks can only run if the launcher stays around
+	postExit, err := hooks.Find(hooks.PostExit)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: "+err.Error()))
+	}
+	if len(postExit) > 0 && (plan.Executor == "" || plan.Executor == launcher.ExecutorExec) {
+		plan.Executor = launcher.ExecutorChild
+	}
+
+	session, err := plan.Execute()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error launching Claude Code: "+err.Error()))
+		return 1
+	}
+	launcher.ShowSessionSummary(session)
+	if err := hooks.Run(hooks.NewInput(hooks.PostExit, plan).WithSession(session)); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: "+err.Error()))
+	}
+	return session.ExitCode
+}
+
+// cliFlags holds the values of the launcher's command line flags
+type cliFlags struct {
+	debug           bool
+	local           bool
+	yolo            bool
+	happy           bool
+	resume          bool
+	continueSession bool
+	blank           bool
+	config          bool
+	zai             bool
+	provider        string
+	skipPreflight   bool
+	// preflightTimeout overrides the preflight timeout from settings
+	preflightTimeout time.Duration
+	dryRun           bool
+	savePlan         string
+	mcp              stringList
+	preset           string
+	model            string
+	account          string
+	target           string
+	supervise        bool
+	worktree         string
+	permissionMode   string
+	allowedTools     stringList
+	disallowedTools  stringList
+	addDirs          stringList
+	prompts          stringList
+	settings         string
+	// settingsFile is the path of the variant named by settings
+	settingsFile string
+	// appendSystemPrompt joins the fragments selected by prompts
+	appendSystemPrompt string
+	// extraArgs are forwarded to Claude Code: settings, then preset, then
+	// everything after "--" on the command line
+	extraArgs []string
+	// requireSandbox refuses yolo on host targets, see config.YoloSettings
+	requireSandbox bool
+	// checkpoints decides when to record a git checkpoint
+	checkpoints config.CheckpointSettings
+}
+
+// registerFlags defines the launcher's flags on fs. It is shared by main and
+// the completion generator so both always agree on the set of flags.
+func registerFlags(fs *flag.FlagSet) *cliFlags {
+	f := &cliFlags{}
+	fs.BoolVar(&f.debug, "debug", false, "Enable debug logging")
+	fs.BoolVar(&f.local, "local", false, "Only check for local MCP configurations, skip global ones")
+	fs.BoolVar(&f.yolo, "yolo", false, "Launch Claude Code with --dangerously-skip-permissions")
+	fs.BoolVar(&f.happy, "happy", false, "Use happy instead of claude command")
+	fs.BoolVar(&f.resume, "r", false, "Launch Claude Code with --resume flag (-r, --resume)")
+	fs.BoolVar(&f.resume, "resume", false, "Launch Claude Code with --resume flag (-r, --resume)")
+	fs.BoolVar(&f.continueSession, "continue", false, "Launch Claude Code with --continue flag (--continue)")
+	fs.BoolVar(&f.config, "c", false, "Always show TUI config interface (overrides other flags) (-c, --config)")
+	fs.BoolVar(&f.config, "config", false, "Always show TUI config interface (overrides other flags) (-c, --config)")
+	fs.BoolVar(&f.blank, "b", false, "Launch Claude Code without MCP servers (skip TUI) (-b, --blank)")
+	fs.BoolVar(&f.blank, "blank", false, "Launch Claude Code without MCP servers (skip TUI) (-b, --blank)")
+	fs.BoolVar(&f.zai, "zai", false, "Use z.ai coding plan (same as --provider zai)")
+	fs.StringVar(&f.provider, "provider", "", "Use the named provider profile from providers.json (comma-separated for failover)")
+	fs.BoolVar(&f.skipPreflight, "skip-preflight", false, "Launch without checking the provider first")
+	fs.DurationVar(&f.preflightTimeout, "preflight-timeout", 0, "Timeout for the provider check (default 5s)")
+	fs.BoolVar(&f.dryRun, "dry-run", false, "Print the resolved command and environment instead of launching")
+	fs.StringVar(&f.savePlan, "save-plan", "", "Save the resolved launch plan to a JSON file instead of launching")
+	fs.Var(&f.mcp, "mcp", "Launch with the named MCP configurations (repeatable, comma-separated)")
+	fs.StringVar(&f.preset, "preset", "", "Launch with a preset from .claude/launcher/config.json")
+	fs.StringVar(&f.target, "target", "", "Launch with the named launch target (claude, happy, npx or one from config.json)")
+	fs.BoolVar(&f.supervise, "supervise", false, "Keep the launcher running as Claude Code's parent to clean up and report afterwards")
+	fs.StringVar(&f.account, "account", "", "Use the named Claude account from config.json (sets CLAUDE_CONFIG_DIR)")
+	fs.StringVar(&f.permissionMode, "permission-mode", "", "Launch Claude Code in a permission mode: default, plan, acceptEdits or bypass")
+	fs.Var(&f.allowedTools, "allowed-tools", "Tool patterns Claude Code may use without asking (repeatable, comma-separated)")
+	fs.Var(&f.disallowedTools, "disallowed-tools", "Tool patterns Claude Code must not use (repeatable, comma-separated)")
+	fs.StringVar(&f.settings, "settings", "", "Launch with the named Claude Code settings variant")
+	fs.Var(&f.prompts, "prompt", "Append the named prompt fragments to the system prompt (repeatable, comma-separated)")
+	fs.Var(&f.addDirs, "add-dir", "Additional directories Claude Code may access (repeatable, comma-separated)")
+	fs.StringVar(&f.worktree, "worktree", "", "Launch in a git worktree for the named branch, creating both if needed")
+	return f
+}
+
+// applyPreset merges a preset into the flags. Boolean options are enabled if
+// either the flag or the preset sets them; MCP names are combined.
+func (f *cliFlags) applyPreset(p config.Preset) {
+	f.yolo = f.yolo || p.Yolo
+	f.happy = f.happy || p.Happy
+	f.resume = f.resume || p.Resume
+	f.continueSession = f.continueSession || p.Continue
+	if f.provider == "" {
+		f.provider = p.Provider
+		if len(p.Providers) > 0 {
+			f.provider = strings.Join(p.Providers, ",")
+		}
+	}
+	f.extraArgs = append(f.extraArgs, p.Args...)
+	if p.Model != "" {
+		f.model = p.Model
+	}
+	if f.account == "" {
+		f.account = p.Account
+	}
+	if f.target == "" {
+		f.target = p.Target
+	}
+	if f.permissionMode == "" {
+		f.permissionMode = p.PermissionMode
+	}
+	f.allowedTools = append(f.allowedTools, p.AllowedTools...)
+	f.disallowedTools = append(f.disallowedTools, p.DisallowedTools...)
+	f.addDirs = append(f.addDirs, p.AddDirs...)
+	f.prompts = append(append(stringList{}, p.Prompts...), f.prompts...)
+	if f.settings == "" {
+		f.settings = p.Settings
+	}
+	f.mcp = append(append(stringList{}, p.MCP...), f.mcp...)
+
+	// A preset without MCP servers still launches directly, like --blank
+	if len(f.mcp) == 0 {
+		f.blank = true
+	}
+}
+
+// launchOptions returns the launch options selected by the flags alone,
+// without any MCP configuration files
+func (f *cliFlags) launchOptions(targets []config.Target, provider config.Provider, account config.Account, cli *claudecli.Info) launcher.Options {
+	opts := launcher.Options{
+		Yolo:               f.yolo,
+		Targets:            targets,
+		Resume:             f.resume,
+		Continue:           f.continueSession,
+		Provider:           provider,
+		Account:            account,
+		Model:              f.model,
+		AppendSystemPrompt: f.appendSystemPrompt,
+		SettingsFile:       f.settingsFile,
+		PermissionMode:     f.permissionMode,
+		AllowedTools:       f.allowedTools,
+		DisallowedTools:    f.disallowedTools,
+		AddDirs:            f.addDirs,
+		ExtraArgs:          f.extraArgs,
+		Supervise:          f.supervise,
+		CLI:                cli,
+		RequireSandbox:     f.requireSandbox,
+	}
+	opts.Checkpoint = f.checkpoints.Enabled(opts.SkipsPermissions())
+	return opts
+}
+
+// passThroughArgs returns the arguments following "--". Positional arguments
+// without a preceding "--" are rejected rather than silently forwarded.
+func passThroughArgs(args []string, rest []string) ([]string, error) {
+	idx := slices.Index(args, "--")
+	if idx < 0 || len(rest) != len(args)-idx-1 {
+		if len(rest) > 0 {
+			return nil, fmt.Errorf("unexpected argument %q (use -- to pass arguments to Claude Code)", rest[0])
+		}
+		return nil, nil
+	}
+	return rest, nil
+}
+
+// stringList is a repeatable flag that also accepts comma-separated values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// selectMCPConfigs resolves MCP configuration names to the selection map used
+// by the TUI and launcher, where index i refers to configs[i-1].
+func selectMCPConfigs(configs []config.MCPConfig, names []string) (map[int]struct{}, error) {
+	selected := make(map[int]struct{})
+	for _, name := range names {
+		cfg, ok := config.FindMCPConfig(configs, name)
+		if !ok {
+			return nil, fmt.Errorf("no MCP configuration named %s", name)
+		}
+		for i, c := range configs {
+			if c.Path == cfg.Path {
+				selected[i+1] = struct{}{}
+			}
+		}
+	}
+	return selected, nil
+}
+
+// selectedMCPFiles returns the paths of the selected configurations in order
+func selectedMCPFiles(configs []config.MCPConfig, selected map[int]struct{}) []string {
+	var files []string
+	for i, c := range configs {
+		if _, ok := selected[i+1]; ok {
+			files = append(files, c.Path)
+		}
+	}
+	return files
+}
+
+// selectPrompts resolves prompt fragment names to the selection map used by
+// the TUI, where index i refers to fragments[i]. Unreadable fragments are
+// rejected.
+func selectPrompts(fragments []config.PromptFragment, names []string) (map[int]struct{}, error) {
+	selected := make(map[int]struct{})
+	for _, name := range names {
+		p, ok := config.FindPrompt(fragments, name)
+		if !ok {
+			return nil, fmt.Errorf("no prompt fragment named %s", name)
+		}
+		if p.Error != "" {
+			return nil, fmt.Errorf("prompt fragment %s: %s", name, p.Error)
+		}
+		for i, f := range fragments {
+			if f.Path == p.Path {
+				selected[i] = struct{}{}
+			}
+		}
+	}
+	return selected, nil
+}
+
+// detectCLI probes the claude binary in PATH. It returns nil when claude
+// cannot be found or probed, in which case every option is offered.
+func detectCLI(debug bool) *claudecli.Info {
+	path, err := exec.LookPath("claude")
+	if err != nil {
+		return nil
+	}
+	info, err := claudecli.Detect(path)
+	if err != nil {
+		if debug {
+			log.Printf("Warning: %v", err)
+		}
+		return nil
+	}
+	return &info
+}
+
+// usesSecrets reports whether any provider takes its token from the secret store
+func usesSecrets(providers []config.Provider) bool {
+	for _, p := range providers {
+		if p.AuthTokenSecret != "" {
+			return true
+		}
+	}
+	return false
+}
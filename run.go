@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/launcher"
+	"cc-launcher/internal/secrets"
+	"cc-launcher/internal/ui"
+)
+
+// runPlan reproduces a launch saved with --save-plan
+func runPlan(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Print the plan instead of running it")
+	executor := fs.String("executor", "", "Run the plan with this executor instead of the saved one")
+	fs.Parse(reorderFlags(fs, args))
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Usage: cc-launcher run [--dry-run] [--executor name] plan.json"))
+		return 2
+	}
+
+	plan, err := launcher.LoadPlan(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		return 1
+	}
+	if *executor != "" {
+		if _, err := launcher.ExecutorFor(*executor); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+		plan.Executor = *executor
+	}
+
+	if *dryRun {
+		launcher.PrintDryRun(os.Stdout, plan)
+		return 0
+	}
+
+	// Temporary files generated when the plan was saved may be gone by now
+	for _, path := range plan.Generated {
+		if _, err := os.Stat(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: generated file "+path+" is missing; save the plan again"))
+			return 1
+		}
+	}
+
+	// Credentials were left out of the plan file and are looked up again
+	config.SetSecretResolver((&secrets.Unlocker{}).Get)
+	plan, err = plan.ResolveSecrets()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		return 1
+	}
+
+	launcher.ShowLaunchMessage("")
+	return execute(plan)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cc-launcher/internal/secrets"
+	"cc-launcher/internal/ui"
+	"github.com/charmbracelet/x/term"
+)
+
+const secretsUsage = "Usage: cc-launcher secrets set|get|rm <name> | secrets list"
+
+// runSecrets manages the encrypted credential store
+func runSecrets(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(secretsUsage))
+		return 2
+	}
+
+	action := args[0]
+	if action == "list" {
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(secretsUsage))
+			return 2
+		}
+	} else if len(args) != 2 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(secretsUsage))
+		return 2
+	}
+
+	path, err := secrets.DefaultPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		return 1
+	}
+
+	if action != "set" && !secrets.Exists(path) {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: no secrets stored yet (add one with: cc-launcher secrets set <name>)"))
+		return 1
+	}
+
+	store, err := openSecretStore(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		return 1
+	}
+
+	switch action {
+	case "set":
+		value, err := readSecretValue(args[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+		store.Set(args[1], value)
+	case "get":
+		value, ok := store.Get(args[1])
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: secret "+args[1]+" not found"))
+			return 1
+		}
+		fmt.Println(value)
+		return 0
+	case "rm":
+		if !store.Delete(args[1]) {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: secret "+args[1]+" not found"))
+			return 1
+		}
+	case "list":
+		for _, name := range store.Names() {
+			fmt.Println(name)
+		}
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(secretsUsage))
+		return 2
+	}
+
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		return 1
+	}
+	return 0
+}
+
+// openSecretStore unlocks the store at path, asking for a new passphrase
+// twice when the store does not exist yet
+func openSecretStore(path string) (*secrets.Store, error) {
+	if secrets.Exists(path) {
+		passphrase, err := secrets.ReadPassphrase("🔐 Secrets passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		return secrets.Open(path, passphrase)
+	}
+
+	passphrase, err := secrets.ReadPassphrase("🔐 New secrets passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	if os.Getenv(secrets.PassphraseEnv) == "" {
+		confirm, err := secrets.ReadPassphrase("🔐 Repeat passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, confirm) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+	return secrets.Open(path, passphrase)
+}
+
+// readSecretValue reads the value to store from stdin when it is piped, or
+// prompts for it without echo
+func readSecretValue(name string) (string, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		data, err := io.ReadAll(bufio.NewReader(os.Stdin))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret from stdin: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+	value, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"syscall"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/ui"
+)
+
+const tmuxUsage = "Usage: cc-launcher tmux attach [session]"
+
+// runTmux gets back to the tmux session opened by a tmux launch target.
+// Without a name it attaches to the session of this project's tmux target.
+func runTmux(args []string) int {
+	if len(args) == 0 || args[0] != "attach" || len(args) > 2 {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError(tmuxUsage))
+		return 2
+	}
+
+	session := ""
+	if len(args) == 2 {
+		session = args[1]
+	} else {
+		var err error
+		if session, err = projectTmuxSession(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			return 1
+		}
+	}
+
+	tmuxPath, err := exec.LookPath("tmux")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: tmux not found in PATH"))
+		return 1
+	}
+	if exec.Command(tmuxPath, "has-session", "-t", "="+session).Run() != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: no tmux session "+session))
+		return 1
+	}
+
+	// Inside tmux, attaching would nest; switch the client instead
+	argv := []string{"tmux", "attach-session", "-t", "=" + session}
+	if os.Getenv("TMUX") != "" {
+		argv = []string{"tmux", "switch-client", "-t", "=" + session}
+	}
+	err = syscall.Exec(tmuxPath, argv, os.Environ())
+	fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+	return 1
+}
+
+// projectTmuxSession returns the session name of the configured tmux
+// targets, or the default session name for the current directory
+func projectTmuxSession() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	settings, err := config.LoadSettings()
+	if err != nil {
+		return "", err
+	}
+
+	var sessions []string
+	for _, t := range settings.LaunchTargets() {
+		if t.Tmux == nil {
+			continue
+		}
+		session := t.Tmux.Session
+		if session == "" {
+			session = config.DefaultTmuxSession(dir)
+		}
+		if !slices.Contains(sessions, session) {
+			sessions = append(sessions, session)
+		}
+	}
+	if len(sessions) > 1 {
+		return "", fmt.Errorf("several tmux sessions configured, name one: %v", sessions)
+	}
+	if len(sessions) == 1 {
+		return sessions[0], nil
+	}
+	return config.DefaultTmuxSession(dir), nil
+}
+
+// tmuxTarget reports whether targets open the panes of a tmux target
+// instead of launching Claude Code
+func tmuxTarget(targets []config.Target) bool {
+	return len(targets) > 0 && targets[0].Tmux != nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/git"
+	"cc-launcher/internal/launcher"
+)
+
+// enterWorktree changes into the git worktree for branch, adding it if the
+// branch is not checked out anywhere yet, and brings the project's .claude
+// directory along as configured. MCP files in opts are made absolute so the
+// selection still resolves. A dry run only reports where it would launch.
+func enterWorktree(opts *launcher.Options, branch string, s config.WorktreeSettings, dryRun bool) error {
+	mode, err := s.ConfigMode()
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	root, err := git.Root(dir)
+	if err != nil {
+		return fmt.Errorf("--worktree needs a git repository: %w", err)
+	}
+	// Launch in the same subdirectory of the worktree
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+
+	path := s.Path(root, branch)
+	if dryRun {
+		fmt.Fprintf(os.Stderr, "🌳 Would launch in the worktree for %s at %s\n", branch, filepath.Join(path, rel))
+		return nil
+	}
+
+	worktree, created, err := git.Worktree(root, branch, path)
+	if err != nil {
+		return err
+	}
+	if created {
+		fmt.Fprintf(os.Stderr, "🌳 Created worktree for %s at %s\n", branch, worktree)
+	} else {
+		fmt.Fprintf(os.Stderr, "🌳 Using worktree for %s at %s\n", branch, worktree)
+	}
+
+	for i, file := range opts.MCPFiles {
+		if opts.MCPFiles[i], err = filepath.Abs(file); err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", file, err)
+		}
+	}
+
+	target := filepath.Join(worktree, rel)
+	if mode != config.WorktreeConfigNone {
+		if err := shareClaudeDir(filepath.Join(dir, ".claude"), filepath.Join(target, ".claude"), mode == config.WorktreeConfigLink); err != nil {
+			return err
+		}
+	}
+	if err := os.Chdir(target); err != nil {
+		return fmt.Errorf("failed to change to worktree: %w", err)
+	}
+	return nil
+}
+
+// shareClaudeDir symlinks or copies the entries of the .claude directory src
+// that dst does not have yet, for example because they are not committed
+func shareClaudeDir(src, dst string, link bool) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", src, err)
+	}
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+
+	for _, entry := range entries {
+		from, to := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+		if _, err := os.Lstat(to); err == nil {
+			continue
+		}
+		if link {
+			err = os.Symlink(from, to)
+		} else {
+			err = copyTree(from, to)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to bring %s into the worktree: %w", from, err)
+		}
+	}
+	return nil
+}
+
+// copyTree copies the file or directory src to dst, keeping permissions and
+// symlinks
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, in); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+}
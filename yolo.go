@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"cc-launcher/internal/config"
+	"cc-launcher/internal/guard"
+	"cc-launcher/internal/launcher"
+	"cc-launcher/internal/preflight"
+	"cc-launcher/internal/ui"
+	"github.com/charmbracelet/x/term"
+)
+
+// launchDirect launches without the TUI, in the worktree for --worktree if
+// set. Launches that skip permission checks pass the yolo guards first; a
+// dry run only reports them.
+func launchDirect(opts launcher.Options, flags *cliFlags, settings config.Settings, checker *preflight.Checker) {
+	// Directories from config.json may be missing on this machine and are
+	// skipped; those from --add-dir or the preset must exist. Configured
+	// directories come first, and relative ones are taken from where the
+	// launcher was started.
+	// The panes of a tmux target run the launcher again, which applies the
+	// configured args, directories and account itself
+	configuredDirs := settings.AddDirs
+	tmux := tmuxTarget(opts.Targets)
+	if tmux {
+		opts.ExtraArgs = opts.ExtraArgs[len(settings.Args):]
+		configuredDirs = nil
+		if flags.account == "" {
+			opts.Account = config.Account{}
+		}
+	}
+
+	var configured []string
+	for _, path := range configuredDirs {
+		if _, err := config.ResolveAddDir(path); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Warning: skipping additional directory: "+err.Error()))
+			continue
+		}
+		configured = append(configured, path)
+	}
+	addDirs, err := config.ResolveAddDirs(append(configured, opts.AddDirs...))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+		os.Exit(1)
+	}
+	opts.AddDirs = addDirs
+
+	if flags.worktree != "" {
+		if err := enterWorktree(&opts, flags.worktree, settings.Worktrees, flags.dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Error: "+err.Error()))
+			os.Exit(1)
+		}
+	}
+	// Skipping permission checks is refused for tmux targets when planning
+	if opts.SkipsPermissions() && !tmux {
+		if err := guardYolo(settings.Yolo, opts.MCPFiles, flags.dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", ui.RenderError("Launch aborted: "+err.Error()))
+			os.Exit(1)
+		}
+	}
+	launch(opts, flags.dryRun, flags.savePlan, checker)
+}
+
+// guardYolo prints the results of the yolo guards that did not pass and
+// asks for typed confirmation when a guard requires it. The returned error
+// means the launch must not go ahead; reportOnly never refuses.
+func guardYolo(s config.YoloSettings, mcpFiles []string, reportOnly bool) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working directory: %w", err)
+	}
+	results := guard.Check(s, dir, mcpFiles)
+	for _, r := range results {
+		if r.Level != guard.Pass {
+			fmt.Fprintln(os.Stderr, ui.RenderGuardResult(r))
+		}
+	}
+	if reportOnly {
+		return nil
+	}
+	if refusal := guard.Refusal(results); refusal != "" {
+		return errors.New(refusal)
+	}
+	if guard.Worst(results) < guard.Confirm {
+		return nil
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return errors.New("confirmation required but stdin is not a terminal")
+	}
+	fmt.Fprintf(os.Stderr, "Type %q to confirm: ", guard.ConfirmWord)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read confirmation: %w", err)
+	}
+	if strings.TrimSpace(line) != guard.ConfirmWord {
+		return errors.New("not confirmed")
+	}
+	return nil
+}